@@ -0,0 +1,76 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import "time"
+
+// Constants for adaptive rows per command.
+const (
+	// Target write latency of one batch if the target latency is not set
+	ADAPTIVE_TARGET_LATENCY = time.Second
+	// Max rows per command is the initial rows per command multiplied by this factor if the max is not set
+	ADAPTIVE_MAX_ROWS_FACTOR = 10
+	// Rows per command grows by the initial rows per command divided by this divisor
+	ADAPTIVE_STEP_DIVISOR = 10
+)
+
+// AdaptiveRows adjusts the number of rows per command to the write latency of the batches
+// in the additive increase, multiplicative decrease (AIMD) way: when a batch is written faster than
+// 3/4 of the target latency, the number of rows grows by a fixed step, and when it is written slower
+// than the target latency, the number of rows is halved. Between them the number of rows is kept,
+// so it settles on the value that is written close to the target latency.
+type AdaptiveRows struct {
+	// Min rows per command. If 0, 1 is used.
+	MinRows int64
+	// Max rows per command. If 0, ADAPTIVE_MAX_ROWS_FACTOR times the initial rows per command is used.
+	MaxRows int64
+	// Target write latency of one batch. If 0, ADAPTIVE_TARGET_LATENCY is used.
+	TargetLatency time.Duration
+	// Current rows per command.
+	rows int64
+	// Number of rows added when the batch is written faster than the target latency.
+	step int64
+}
+
+// Start sets the current rows per command to the given initial number limited by the min and max rows,
+// and returns it. The step of the increase is derived from the initial number.
+func (ar *AdaptiveRows) Start(rows int64) int64 {
+	if ar.MinRows <= 0 {
+		ar.MinRows = 1
+	}
+	if rows < ar.MinRows {
+		rows = ar.MinRows
+	}
+	if ar.MaxRows <= 0 {
+		ar.MaxRows = rows * ADAPTIVE_MAX_ROWS_FACTOR
+	}
+	if ar.MaxRows < ar.MinRows {
+		ar.MaxRows = ar.MinRows
+	}
+	if ar.TargetLatency <= 0 {
+		ar.TargetLatency = ADAPTIVE_TARGET_LATENCY
+	}
+	ar.rows = min(rows, ar.MaxRows)
+	ar.step = max(rows/ADAPTIVE_STEP_DIVISOR, 1)
+	return ar.rows
+}
+
+// Adjust changes the current rows per command according to the given write latency of a batch
+// with the current number of rows. It returns the new rows per command and true if it has changed.
+func (ar *AdaptiveRows) Adjust(latency time.Duration) (int64, bool) {
+	rows := ar.rows
+	if latency > ar.TargetLatency {
+		rows = max(rows/2, ar.MinRows)
+	} else if latency < ar.TargetLatency*3/4 {
+		rows = min(rows+ar.step, ar.MaxRows)
+	}
+	changed := rows != ar.rows
+	ar.rows = rows
+	return rows, changed
+}
+
+// GetRows returns the current rows per command.
+func (ar *AdaptiveRows) GetRows() int64 {
+	return ar.rows
+}
@@ -0,0 +1,55 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAdaptiveRowsStart verifies the defaults of the bounds and the target latency
+// and that the initial rows are limited by the bounds.
+func TestAdaptiveRowsStart(t *testing.T) {
+	ar := AdaptiveRows{}
+	assert.Equal(t, int64(100), ar.Start(100))
+	assert.Equal(t, int64(1), ar.MinRows)
+	assert.Equal(t, int64(1000), ar.MaxRows)
+	assert.Equal(t, ADAPTIVE_TARGET_LATENCY, ar.TargetLatency)
+
+	ar = AdaptiveRows{MinRows: 200, MaxRows: 500}
+	assert.Equal(t, int64(200), ar.Start(100))
+	ar = AdaptiveRows{MinRows: 10, MaxRows: 50}
+	assert.Equal(t, int64(50), ar.Start(100))
+	assert.Equal(t, int64(50), ar.GetRows())
+}
+
+// TestAdaptiveRowsAdjust verifies that the rows grow additively while the latency is below the target,
+// are halved when it is above the target, are kept close to the target and stay within the bounds.
+func TestAdaptiveRowsAdjust(t *testing.T) {
+	ar := AdaptiveRows{MinRows: 30, MaxRows: 120, TargetLatency: 100 * time.Millisecond}
+	ar.Start(100)
+	rows, changed := ar.Adjust(10 * time.Millisecond)
+	assert.True(t, changed)
+	assert.Equal(t, int64(110), rows)
+	rows, _ = ar.Adjust(10 * time.Millisecond)
+	assert.Equal(t, int64(120), rows)
+	rows, changed = ar.Adjust(10 * time.Millisecond)
+	assert.False(t, changed)
+	assert.Equal(t, int64(120), rows)
+
+	rows, changed = ar.Adjust(90 * time.Millisecond)
+	assert.False(t, changed)
+	assert.Equal(t, int64(120), rows)
+
+	rows, changed = ar.Adjust(200 * time.Millisecond)
+	assert.True(t, changed)
+	assert.Equal(t, int64(60), rows)
+	rows, _ = ar.Adjust(200 * time.Millisecond)
+	assert.Equal(t, int64(30), rows)
+	rows, changed = ar.Adjust(200 * time.Millisecond)
+	assert.False(t, changed)
+	assert.Equal(t, int64(30), rows)
+}
@@ -0,0 +1,301 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"context"
+	"copysqldatatool/internal/appdb"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// Layouts of the date and time strings converted to the Date and DateTime columns.
+var nativeTimeLayouts = []string{appdb.TIME_LAYOUT, time.DateTime, time.DateOnly, time.RFC3339Nano}
+
+// Time zone of the DateTime and DateTime64 column types, for example DateTime('Europe/Berlin').
+var nativeTimeZone = regexp.MustCompile(`DateTime(?:64)?\((?:\d+,\s*)?'([^']+)'\)`)
+
+// ClickHouseProcessor writes rows to a ClickHouse table with the native batch API of clickhouse-go:
+// each batch of rows is appended to a batch prepared for the columns and sent in the columnar format
+// instead of the INSERT statement in text. The values are converted to the Go types of the destination
+// columns, and the arrays, maps and tuples read from ClickHouse are written as they are.
+// The processor opens its own native connection with the DSN of the embedded DbProcessor, whose
+// TableName, WriteTimeout and Retry policy are used.
+type ClickHouseProcessor struct {
+	// Processor of the destination connection, which provides the DSN, the table and the write settings.
+	*DbProcessor
+	// InsertCommand is the part of SQL command used for inserting data, for example "INSERT INTO".
+	InsertCommand string
+	// Settings of the INSERT queries, for example async_insert or insert_quorum.
+	Settings map[string]any
+	// Native connection opened by the first written batch.
+	conn driver.Conn
+}
+
+// Write is not supported by ClickHouseProcessor, as it writes row values instead of SQL statements.
+// See: app.RowsProcessorInterface.Write, app.RowsWriterInterface.WriteRows
+func (cp *ClickHouseProcessor) Write(buffer []string, data []any) error {
+	return fmt.Errorf("clickhouse processor does not write SQL statements")
+}
+
+// WriteRows sends the given rows with the given columns to the table as one native batch.
+// A batch failed with a transient error is prepared and sent again according to the Retry policy
+// only if it has not been sent or the server has rejected it, for example with TOO_MANY_PARTS.
+// After a network error during the sending the status of the insert is unknown, so it is not retried.
+// See: app.RowsWriterInterface.WriteRows
+func (cp *ClickHouseProcessor) WriteRows(columns []appdb.Column, rows [][]any) error {
+	if cp.DbProcessor == nil || cp.AppDb == nil {
+		return fmt.Errorf("db is not set")
+	}
+	if err := cp.open(); err != nil {
+		return fmt.Errorf("error opening native connection: %w", err)
+	}
+	dialect := cp.AppDb.GetDialect()
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = dialect.QuoteIdentifier(column.Name)
+	}
+	query := fmt.Sprintf("%s %s (%s)", cp.InsertCommand, cp.TableName, strings.Join(names, ", "))
+	return cp.Retry.DoWrite(cp.getContext(), dialect, func(attempt int) error {
+		return cp.send(query, rows)
+	})
+}
+
+// open opens the native connection if it is not open yet.
+func (cp *ClickHouseProcessor) open() error {
+	if cp.conn != nil {
+		return nil
+	}
+	options, err := clickhouse.ParseDSN(cp.AppDb.Dsn)
+	if err != nil {
+		return err
+	}
+	conn, err := clickhouse.Open(options)
+	if err != nil {
+		return err
+	}
+	cp.conn = conn
+	return nil
+}
+
+// send prepares the batch of the given INSERT query, appends the given rows converted
+// to the types of the destination columns and sends it. The time is limited by WriteTimeout.
+// The errors of preparing the batch and appending the rows are raised before the batch is sent,
+// so they are marked by appdb.NotSent.
+func (cp *ClickHouseProcessor) send(query string, rows [][]any) error {
+	ctx, cancel := appdb.WithTimeout(context.Background(), cp.WriteTimeout)
+	defer cancel()
+	if settings := cp.getSettings(); len(settings) > 0 {
+		ctx = clickhouse.Context(ctx, clickhouse.WithSettings(settings))
+	}
+	batch, err := cp.conn.PrepareBatch(ctx, query)
+	if err != nil {
+		return appdb.NotSent(fmt.Errorf("error preparing batch: %w", appdb.TimeoutError(ctx, err)))
+	}
+	defer batch.Abort()
+	var serverLocation *time.Location
+	if version, err := cp.conn.ServerVersion(); err == nil {
+		serverLocation = version.Timezone
+	}
+	types := make([]reflect.Type, 0)
+	locations := make([]*time.Location, 0)
+	for _, column := range batch.Columns() {
+		types = append(types, column.ScanType())
+		locations = append(locations, GetNativeLocation(string(column.Type()), serverLocation))
+	}
+	values := make([]any, len(types))
+	for _, row := range rows {
+		if len(row) != len(types) {
+			return fmt.Errorf("row has %d values for %d columns", len(row), len(types))
+		}
+		for i, val := range row {
+			if values[i], err = ConvertNativeValue(val, types[i], locations[i]); err != nil {
+				return fmt.Errorf("error converting column %s: %w", batch.Columns()[i].Name(), err)
+			}
+		}
+		if err := batch.Append(values...); err != nil {
+			return appdb.NotSent(fmt.Errorf("error appending row: %w", err))
+		}
+	}
+	if err := batch.Send(); err != nil {
+		return fmt.Errorf("error writing to database: %w", appdb.TimeoutError(ctx, err))
+	}
+	return nil
+}
+
+// getSettings returns the Settings as the settings of the query. The numbers decoded from JSON
+// without a fraction are converted to integers, as ClickHouse does not accept them in the exponent format.
+func (cp *ClickHouseProcessor) getSettings() clickhouse.Settings {
+	settings := clickhouse.Settings{}
+	for name, value := range cp.Settings {
+		if number, ok := value.(float64); ok && number == math.Trunc(number) && math.Abs(number) < math.MaxInt64 {
+			value = int64(number)
+		}
+		settings[name] = value
+	}
+	return settings
+}
+
+// Close closes the native connection if it has been opened.
+func (cp *ClickHouseProcessor) Close() error {
+	if cp.conn == nil {
+		return nil
+	}
+	err := cp.conn.Close()
+	cp.conn = nil
+	return err
+}
+
+// GetNativeLocation returns the time zone of the given type of a destination column: the time zone
+// of the DateTime or DateTime64 type if it is set, otherwise the given time zone of the server or UTC.
+func GetNativeLocation(columnType string, server *time.Location) *time.Location {
+	if match := nativeTimeZone.FindStringSubmatch(columnType); match != nil {
+		if location, err := time.LoadLocation(match[1]); err == nil {
+			return location
+		}
+	}
+	if server != nil {
+		return server
+	}
+	return time.UTC
+}
+
+// ConvertNativeValue converts the given value read from the source to the given Go type of the destination
+// column of the native batch. Nullable columns, whose type is a pointer, take NULL values and the values
+// of the type they point to. Numbers, booleans, strings and times are converted between each other,
+// the numbers are checked for overflow. The times without a time zone are in the given time zone
+// of the column. The values of other types are returned unchanged, so the driver converts them,
+// for example strings to decimals and UUIDs, and byte slices are returned as strings.
+func ConvertNativeValue(val any, target reflect.Type, location *time.Location) (any, error) {
+	if val == nil {
+		return nil, nil
+	}
+	if target.Kind() == reflect.Pointer {
+		target = target.Elem()
+	}
+	value := reflect.ValueOf(val)
+	if value.Type().AssignableTo(target) {
+		return val, nil
+	}
+	if bytes, ok := val.([]byte); ok {
+		val = string(bytes)
+		value = reflect.ValueOf(val)
+	}
+	switch target.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(formatTextValue(val)).Convert(target).Interface(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := toNativeInt(value)
+		if err != nil {
+			return nil, err
+		}
+		if reflect.Zero(target).OverflowInt(number) {
+			return nil, fmt.Errorf("value %v overflows %s", val, target)
+		}
+		return reflect.ValueOf(number).Convert(target).Interface(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, err := toNativeUint(value)
+		if err != nil {
+			return nil, err
+		}
+		if reflect.Zero(target).OverflowUint(number) {
+			return nil, fmt.Errorf("value %v overflows %s", val, target)
+		}
+		return reflect.ValueOf(number).Convert(target).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		number, err := toNativeFloat(value)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(number).Convert(target).Interface(), nil
+	case reflect.Bool:
+		switch value.Kind() {
+		case reflect.String:
+			return strconv.ParseBool(value.String())
+		default:
+			number, err := toNativeFloat(value)
+			return number != 0, err
+		}
+	}
+	if target == reflect.TypeOf(time.Time{}) && value.Kind() == reflect.String {
+		for _, layout := range nativeTimeLayouts {
+			if t, err := time.ParseInLocation(layout, value.String(), location); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid time: %s", value.String())
+	}
+	return val, nil
+}
+
+// toNativeInt returns the given number, boolean or string as a signed integer.
+// A float must not have a fraction.
+func toNativeInt(value reflect.Value) (int64, error) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("value %d overflows int64", value.Uint())
+		}
+		return int64(value.Uint()), nil
+	case reflect.String:
+		return strconv.ParseInt(strings.TrimSpace(value.String()), 10, 64)
+	}
+	number, err := toNativeFloat(value)
+	if err != nil {
+		return 0, err
+	}
+	if number != math.Trunc(number) || math.Abs(number) >= math.MaxInt64 {
+		return 0, fmt.Errorf("value %v is not an integer", number)
+	}
+	return int64(number), nil
+}
+
+// toNativeUint returns the given number, boolean or string as an unsigned integer.
+// A negative number fails.
+func toNativeUint(value reflect.Value) (uint64, error) {
+	switch value.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.Uint(), nil
+	case reflect.String:
+		return strconv.ParseUint(strings.TrimSpace(value.String()), 10, 64)
+	}
+	number, err := toNativeInt(value)
+	if err != nil {
+		return 0, err
+	}
+	if number < 0 {
+		return 0, fmt.Errorf("value %d is negative", number)
+	}
+	return uint64(number), nil
+}
+
+// toNativeFloat returns the given number, boolean or string as a float.
+func toNativeFloat(value reflect.Value) (float64, error) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), nil
+	case reflect.Bool:
+		if value.Bool() {
+			return 1, nil
+		}
+		return 0, nil
+	case reflect.String:
+		return strconv.ParseFloat(strings.TrimSpace(value.String()), 64)
+	}
+	return 0, fmt.Errorf("value %v of type %s is not a number", value.Interface(), value.Type())
+}
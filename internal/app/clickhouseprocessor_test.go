@@ -0,0 +1,132 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"copysqldatatool/internal/appdb"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConvertNativeValue verifies that the values read from the source are converted to the Go types
+// of the destination columns, including the Nullable columns, and that the arrays and maps are kept.
+func TestConvertNativeValue(t *testing.T) {
+	date := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		val      any
+		target   any
+		expected any
+	}{
+		{int64(7), int32(0), int32(7)},
+		{[]byte("42"), uint8(0), uint8(42)},
+		{"-3", int64(0), int64(-3)},
+		{float64(5), uint16(0), uint16(5)},
+		{true, int8(0), int8(1)},
+		{[]byte("1.5"), float32(0), float32(1.5)},
+		{int64(2), float64(0), float64(2)},
+		{[]byte("abc"), "", "abc"},
+		{int64(10), "", "10"},
+		{int64(1), false, true},
+		{"false", false, false},
+		{[]byte("2025-01-02 03:04:05"), time.Time{}, date},
+		{"2025-01-02", time.Time{}, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{date, time.Time{}, date},
+		{[]string{"a", "b"}, []string{}, []string{"a", "b"}},
+		{map[string]uint64{"a": 1}, map[string]uint64{}, map[string]uint64{"a": 1}},
+		{[]byte("12.34"), struct{}{}, "12.34"},
+	}
+	for _, test := range tests {
+		actual, err := ConvertNativeValue(test.val, reflect.TypeOf(test.target), time.UTC)
+		assert.Nil(t, err, test.val)
+		assert.Equal(t, test.expected, actual, test.val)
+	}
+
+	// Nullable columns take NULL values and the values of the type they point to
+	actual, err := ConvertNativeValue(nil, reflect.TypeOf((*int32)(nil)), time.UTC)
+	assert.Nil(t, err)
+	assert.Nil(t, actual)
+	actual, err = ConvertNativeValue([]byte("5"), reflect.TypeOf((*int32)(nil)), time.UTC)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(5), actual)
+}
+
+// TestConvertNativeValueLocation verifies that the times without a time zone are converted in the time zone
+// of the column, which is taken from the DateTime type or from the server, and the times with a time zone are kept.
+func TestConvertNativeValueLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.Nil(t, err)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.Nil(t, err)
+	for columnType, expected := range map[string]*time.Location{
+		"DateTime('Europe/Berlin')":                           berlin,
+		"Nullable(DateTime64(3, 'Europe/Berlin'))":            berlin,
+		"LowCardinality(Nullable(DateTime('Europe/Berlin')))": berlin,
+		"DateTime":          tokyo,
+		"DateTime64(6)":     tokyo,
+		"Date":              tokyo,
+		"DateTime('Wrong')": tokyo,
+	} {
+		assert.Equal(t, expected.String(), GetNativeLocation(columnType, tokyo).String(), columnType)
+	}
+	assert.Equal(t, time.UTC, GetNativeLocation("DateTime", nil))
+
+	target := reflect.TypeOf(time.Time{})
+	actual, err := ConvertNativeValue([]byte("2025-01-02 03:04:05"), target, berlin)
+	assert.Nil(t, err)
+	assert.True(t, time.Date(2025, 1, 2, 2, 4, 5, 0, time.UTC).Equal(actual.(time.Time)), actual)
+	actual, err = ConvertNativeValue("2025-07-02 03:04:05.5", target, berlin)
+	assert.Nil(t, err)
+	assert.True(t, time.Date(2025, 7, 2, 1, 4, 5, 500000000, time.UTC).Equal(actual.(time.Time)), actual)
+	actual, err = ConvertNativeValue("2025-01-02T03:04:05Z", target, berlin)
+	assert.Nil(t, err)
+	assert.True(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC).Equal(actual.(time.Time)), actual)
+}
+
+// TestConvertNativeValueErrors verifies that the values that do not fit the types of the destination columns fail.
+func TestConvertNativeValueErrors(t *testing.T) {
+	tests := []struct {
+		val    any
+		target any
+	}{
+		{int64(300), uint8(0)},
+		{int64(-1), uint32(0)},
+		{"abc", int64(0)},
+		{1.5, int64(0)},
+		{uint64(1 << 63), int64(0)},
+		{"yes", false},
+		{"2025-13-45", time.Time{}},
+		{[]string{"a"}, int64(0)},
+	}
+	for _, test := range tests {
+		_, err := ConvertNativeValue(test.val, reflect.TypeOf(test.target), time.UTC)
+		assert.Error(t, err, test.val)
+	}
+}
+
+// TestClickHouseProcessorSettings verifies that the numbers of the settings decoded from JSON without
+// a fraction are passed as integers and the other settings are kept.
+func TestClickHouseProcessorSettings(t *testing.T) {
+	cp := ClickHouseProcessor{Settings: map[string]any{"async_insert": float64(1), "max_insert_block_size": float64(1000000), "ratio": 0.5, "mode": "auto"}}
+	expected := clickhouse.Settings{"async_insert": int64(1), "max_insert_block_size": int64(1000000), "ratio": 0.5, "mode": "auto"}
+	assert.Equal(t, expected, cp.getSettings())
+}
+
+// TestClickHouseProcessorWrite verifies that the ClickHouseProcessor does not write SQL statements
+// and fails to write the rows without the database or the server.
+func TestClickHouseProcessorWrite(t *testing.T) {
+	cp := ClickHouseProcessor{}
+	assert.Error(t, cp.Write([]string{INSERT_3}, nil))
+	assert.ErrorContains(t, cp.WriteRows([]appdb.Column{{Name: "id"}}, [][]any{{1}}), "db is not set")
+	assert.Nil(t, cp.Close())
+
+	// The native connection is opened with the DSN of the database and the batch fails without the server
+	cp.DbProcessor = &DbProcessor{AppDb: &appdb.AppDb{Driver: appdb.DRIVER_CLICKHOUSE, Dsn: "clickhouse://127.0.0.1:1/default?dial_timeout=1s"}, TableName: TBL_NAME}
+	cp.InsertCommand = INSERT_INTO
+	assert.ErrorContains(t, cp.WriteRows([]appdb.Column{{Name: "id"}}, [][]any{{1}}), "error preparing batch")
+	assert.Nil(t, cp.Close())
+}
@@ -0,0 +1,113 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"copysqldatatool/internal/appdb"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// CsvProcessor writes rows to a CSV or TSV file.
+// The first written batch starts with a header row with the column names.
+// Fields are quoted according to RFC 4180 when they contain the delimiter, quotes or line breaks.
+type CsvProcessor struct {
+	// Reference to an open file for writing.
+	File FileInterface
+	// Field delimiter. If 0, a comma is used.
+	Delimiter rune
+	// String written for NULL values. If empty, NULL values are written as empty fields.
+	NullValue string
+	// Do not write the header row. Used when rows are appended to an existing file.
+	SkipHeader bool
+	// CSV writer of the file.
+	writer *csv.Writer
+}
+
+// Write is not supported by CsvProcessor, as it writes row values instead of SQL statements.
+// See: app.RowsProcessorInterface.Write, app.RowsWriterInterface.WriteRows
+func (cp *CsvProcessor) Write(buffer []string, data []any) error {
+	return fmt.Errorf("csv processor does not write SQL statements")
+}
+
+// WriteRows writes the given rows to the file, one record per row.
+// The header row with the names of the given columns is written before the first rows unless SkipHeader is set.
+// The written records are flushed to the file before the method returns.
+// See: app.RowsWriterInterface.WriteRows
+func (cp *CsvProcessor) WriteRows(columns []appdb.Column, rows [][]any) error {
+	if cp.File == nil {
+		return fmt.Errorf("file is not set")
+	}
+	if cp.writer == nil {
+		cp.writer = csv.NewWriter(cp.File)
+		if cp.Delimiter != 0 {
+			cp.writer.Comma = cp.Delimiter
+		}
+		if !cp.SkipHeader {
+			header := make([]string, len(columns))
+			for i, column := range columns {
+				header[i] = column.Name
+			}
+			if err := cp.writer.Write(header); err != nil {
+				return err
+			}
+		}
+	}
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, val := range row {
+			record[i] = cp.FormatValue(val)
+		}
+		if err := cp.writer.Write(record); err != nil {
+			return err
+		}
+	}
+	cp.writer.Flush()
+	return cp.writer.Error()
+}
+
+// FormatValue formats a value as a CSV field.
+// NULL values are formatted as NullValue, other values are formatted by formatTextValue.
+func (cp *CsvProcessor) FormatValue(val any) string {
+	if val == nil {
+		return cp.NullValue
+	}
+	return formatTextValue(val)
+}
+
+// formatTextValue formats a value as text for the file formats without SQL quoting.
+// Byte slices and strings are returned as is, dates are formatted in the layout "YYYY-MM-DD HH:MM:SS.ffffff".
+// NULL values are formatted as an empty string.
+func formatTextValue(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(appdb.TIME_LAYOUT)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// GetProcessedMsg returns a message indicating the number of rows processed
+// to the file specified by the File field.
+// See: app.RowsProcessorInterface.GetProcessedMsg
+func (cp *CsvProcessor) GetProcessedMsg() string {
+	if cp.File == nil {
+		return fmt.Errorf("file is not set").Error()
+	}
+	return fmt.Sprint("Rows processed to file: ", cp.File.Name())
+}
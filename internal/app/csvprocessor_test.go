@@ -0,0 +1,58 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"copysqldatatool/internal/appdb"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCsvWriteRowsNilFile verifies that WriteRows returns an error when the File field is not set.
+func TestCsvWriteRowsNilFile(t *testing.T) {
+	p := CsvProcessor{}
+	err := p.WriteRows([]appdb.Column{{Name: "id"}}, [][]any{{1}})
+	assert.NotNil(t, err)
+}
+
+// TestCsvWriteRows verifies that the header is written once before the first rows,
+// fields are quoted according to RFC 4180 and NULL values are written as NullValue.
+func TestCsvWriteRows(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "test.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	p := CsvProcessor{File: file, NullValue: "NULL"}
+	columns := []appdb.Column{{Name: "id"}, {Name: "name"}, {Name: "created"}}
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	err = p.WriteRows(columns, [][]any{{int64(1), []byte(`a,"b"`), created}})
+	assert.Nil(t, err)
+	err = p.WriteRows(columns, [][]any{{int64(2), "c\nd", nil}})
+	assert.Nil(t, err)
+	data, err := os.ReadFile(file.Name())
+	assert.Nil(t, err)
+	expected := "id,name,created\n1,\"a,\"\"b\"\"\",2025-01-02 03:04:05\n2,\"c\nd\",NULL\n"
+	assert.Equal(t, expected, string(data))
+}
+
+// TestCsvWriteRowsTsv verifies that the fields are separated by the Delimiter
+// and the header is not written when SkipHeader is set.
+func TestCsvWriteRowsTsv(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "test.tsv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	p := CsvProcessor{File: file, Delimiter: '\t', SkipHeader: true}
+	err = p.WriteRows([]appdb.Column{{Name: "id"}, {Name: "name"}}, [][]any{{int64(1), "a b"}, {2.5, nil}})
+	assert.Nil(t, err)
+	data, err := os.ReadFile(file.Name())
+	assert.Nil(t, err)
+	assert.Equal(t, "1\ta b\n2.5\t\n", string(data))
+}
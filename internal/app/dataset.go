@@ -3,12 +3,22 @@
 // Copyright (c) 2025 Aleksei Grigorev
 package app
 
-// Constants. Statement types for SQL insert operations.
+import "time"
+
+// Constants. Statement types for SQL insert operations and actions on processor errors.
 const (
 	// Statement type for prepared statements (INSERT INTO ... VALUES (?, ?, ...))
 	STATEMENT_TYPE_PREPARED = "prepared"
 	// Statement type for raw SQL statements (INSERT INTO ... VALUES ('value1', 'value2', ...))
 	STATEMENT_TYPE_RAW = "raw"
+	// Stop processing when any processor fails
+	SINK_ERROR_ABORT = "abort"
+	// Continue processing with the processors that have not failed
+	SINK_ERROR_CONTINUE = "continue"
+	// Stop processing when a batch is rejected by the destination
+	ROW_ERROR_ABORT = "abort"
+	// Split the batch rejected by the destination to find the bad rows and write them to the dead letter writer
+	ROW_ERROR_DEADLETTER = "deadletter"
 )
 
 // Dataset represents a database dataset configuration with details for SQL insertion operations.
@@ -19,10 +29,38 @@ type Dataset struct {
 	InsertCommand string
 	// Table name
 	TableName string
+	// Driver name of the destination database used to choose the SQL dialect.
+	// For example, "mysql", "clickhouse", "postgres". Empty value means MySQL dialect.
+	Driver string
 	// Rows per command to insert multiple rows at once
 	RowsPerCommand int64
+	// Adjust the rows per command to the write latency of the batches, starting from RowsPerCommand.
+	// See: AdaptiveRows
+	AdaptiveRows bool
+	// Min rows per command for AdaptiveRows. If 0, 1 is used.
+	MinRowsPerCommand int64
+	// Max rows per command for AdaptiveRows. If 0, ADAPTIVE_MAX_ROWS_FACTOR times RowsPerCommand is used.
+	MaxRowsPerCommand int64
+	// Target write latency of one batch for AdaptiveRows. If 0, ADAPTIVE_TARGET_LATENCY is used.
+	TargetLatency time.Duration
+	// Read, format and write the rows in parallel goroutines, so the source is read while the batches are written.
+	// See: RowsProcessor.processPipeline
+	Pipeline bool
+	// Number of batches formatted ahead of the writer for Pipeline. If 0, PIPELINE_DEPTH is used.
+	PipelineDepth int
+	// Max size in bytes of one INSERT statement with the values of the prepared statement.
+	// The batch is written before the row that would exceed it. If 0, the size is not limited.
+	MaxBatchBytes int64
 	// Type of SQL statement to be used for the insert operation.
 	// prepared, simple, custom etc.
 	// See: STATEMENT_TYPE_PREPARED, STATEMENT_TYPE_RAW
 	SqlStatementType string
+	// Action when one of the processors fails to write rows.
+	// abort or continue. Empty value means abort.
+	// See: SINK_ERROR_ABORT, SINK_ERROR_CONTINUE
+	OnSinkError string
+	// Action when a processor that writes SQL statements rejects a batch because of bad rows.
+	// abort or deadletter. Empty value means abort.
+	// See: ROW_ERROR_ABORT, ROW_ERROR_DEADLETTER
+	OnRowError string
 }
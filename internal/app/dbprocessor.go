@@ -4,9 +4,11 @@
 package app
 
 import (
+	"context"
 	"copysqldatatool/internal/appdb"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // DbProcessor represents a database processor that manages database operations
@@ -16,23 +18,167 @@ type DbProcessor struct {
 	AppDb *appdb.AppDb
 	// Name of the table to be used in database operations.
 	TableName string
+	// Number of batches written in one transaction. If 0, each batch is written in autocommit mode.
+	BatchesPerTransaction int64
+	// Write all batches in one transaction, which is committed by Commit when the dataset is completed.
+	// BatchesPerTransaction is not used in this case.
+	DatasetTransaction bool
+	// Max time of writing one batch. If 0, the time is not limited.
+	// A batch that exceeds it fails with an error that wraps appdb.ErrTimeout.
+	WriteTimeout time.Duration
+	// Retry policy of the batches failed with a transient error. The failed batch is written again,
+	// after a lost connection the connection is reopened and the SessionStart script is executed again.
+	// A batch that fails in a transaction with other uncommitted batches is not retried, as they are lost with it.
+	// A batch written in autocommit mode is retried only if the error proves that it has not been committed.
+	Retry appdb.RetryPolicy
+	// Script executed on the destination database when the connection is reopened before a retry.
+	SessionStart string
+	// Number of batches written in the active transaction.
+	batches int64
+	// Context of the processing, which stops waiting for the retries when it is done.
+	ctx context.Context
+}
+
+// SetContext sets the context of the processing, which stops waiting for the retries of a failed batch
+// when it is done. The statement being executed is limited only by WriteTimeout.
+// See: app.RowsProcessorContextInterface.SetContext
+func (db *DbProcessor) SetContext(ctx context.Context) {
+	db.ctx = ctx
+}
+
+// getContext returns the context of the processing or the background context if it is not set.
+func (db *DbProcessor) getContext() context.Context {
+	if db.ctx == nil {
+		return context.Background()
+	}
+	return db.ctx
 }
 
 // Write executes a SQL statement with the given data on the database connection AppDb using the Exec method.
 // The SQL statement is built by joining the strings in buffer with a space in between.
+// If BatchesPerTransaction is set, the statement is executed in a transaction that is committed
+// after the given number of batches. If DatasetTransaction is set, the transaction is committed
+// only by Commit. The execution is limited by WriteTimeout.
+// A statement failed with a transient error is retried according to the Retry policy.
 // The method returns an error if the execution of the SQL statement fails.
 // See: app.RowsProcessorInterface.Write
 func (db *DbProcessor) Write(buffer []string, data []any) error {
 	if db.AppDb == nil {
 		return fmt.Errorf("db is not set")
 	}
-	_, err := db.AppDb.Exec(strings.Join(buffer, ""), data...)
+	return db.execute(strings.Join(buffer, ""), data)
+}
+
+// execute executes the statement of one batch with the given data in the transaction of the batches
+// if they are written in transactions, and retries it according to the Retry policy.
+// See: DbProcessor.Write
+func (db *DbProcessor) execute(query string, data []any) error {
+	dialect := db.AppDb.GetDialect()
+	err := db.exec(query, data)
+	for attempt := 1; err != nil && db.canRetry(attempt, dialect, err); attempt++ {
+		if db.Retry.Wait(db.getContext(), attempt, err) != nil {
+			break
+		}
+		if err = db.recover(err); err == nil {
+			err = db.exec(query, data)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !db.isTransactional() {
+		return nil
+	}
+	db.batches++
+	if !db.DatasetTransaction && db.batches >= db.BatchesPerTransaction {
+		return db.Commit()
+	}
+	return nil
+}
+
+// canRetry returns true if the batch failed with the given error at the given 1-based attempt can be written again.
+// The first batch of a transaction is rolled back with it, while a batch written in autocommit mode may have been
+// committed before the connection was lost, so it is retried only if the error proves that it has not been applied.
+func (db *DbProcessor) canRetry(attempt int, dialect appdb.DialectInterface, err error) bool {
+	if db.batches > 0 {
+		return false
+	}
+	if db.isTransactional() {
+		return db.Retry.CanRetry(attempt, dialect, err)
+	}
+	return db.Retry.CanRetryWrite(attempt, dialect, err)
+}
+
+// isTransactional returns true if the batches are written in transactions.
+func (db *DbProcessor) isTransactional() bool {
+	return db.DatasetTransaction || db.BatchesPerTransaction > 0
+}
+
+// exec executes the statement of one batch, starting a transaction if the batches are written in transactions.
+func (db *DbProcessor) exec(query string, data []any) error {
+	if db.isTransactional() && !db.AppDb.InTransaction() {
+		if err := db.AppDb.Begin(); err != nil {
+			return appdb.NotSent(fmt.Errorf("error starting transaction: %w", err))
+		}
+	}
+	ctx, cancel := appdb.WithTimeout(context.Background(), db.WriteTimeout)
+	defer cancel()
+	_, err := db.AppDb.ExecContext(ctx, query, data...)
 	if err != nil {
 		return fmt.Errorf("error writing to database: %w", err)
 	}
 	return nil
 }
 
+// recover prepares the connection for writing the batch again after the given error.
+// The failed transaction is rolled back, and if the connection has been lost, it is reopened
+// and the SessionStart script is executed again.
+func (db *DbProcessor) recover(err error) error {
+	// The error of the rollback is ignored, as the database may have already rolled back the failed transaction
+	db.AppDb.Rollback()
+	if !appdb.IsConnectionError(err) {
+		return nil
+	}
+	if err := db.AppDb.Reopen(); err != nil {
+		return appdb.NotSent(fmt.Errorf("error reconnecting to the database: %w", err))
+	}
+	if db.SessionStart != "" {
+		if err := db.AppDb.ExecMultiple(db.SessionStart); err != nil {
+			return appdb.NotSent(fmt.Errorf("error executing on_insert_session_start: %w", err))
+		}
+	}
+	return nil
+}
+
+// InTransaction returns true if there are written batches that are not committed yet.
+// See: app.RowsProcessorTransactionInterface.InTransaction
+func (db *DbProcessor) InTransaction() bool {
+	return db.AppDb != nil && db.AppDb.InTransaction()
+}
+
+// Commit commits the active transaction.
+// See: app.RowsProcessorTransactionInterface.Commit
+func (db *DbProcessor) Commit() error {
+	if db.AppDb == nil {
+		return fmt.Errorf("db is not set")
+	}
+	db.batches = 0
+	if err := db.AppDb.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
+}
+
+// Rollback rolls back the active transaction.
+// See: app.RowsProcessorTransactionInterface.Rollback
+func (db *DbProcessor) Rollback() error {
+	if db.AppDb == nil {
+		return fmt.Errorf("db is not set")
+	}
+	db.batches = 0
+	return db.AppDb.Rollback()
+}
+
 // GetProcessedMsg returns a message indicating the number of rows processed
 // to the database table specified by the Table field. This message is useful
 // for logging and debugging purposes to confirm successful data processing.
@@ -4,10 +4,13 @@
 package app
 
 import (
+	"context"
 	"copysqldatatool/internal/appdb"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -106,3 +109,120 @@ func TestWriteDbPreparedStatement(t *testing.T) {
 	fmt.Println(actual)
 	assert.Contains(t, actual, TBL_NAME)
 }
+
+// lockSqliteTable starts a transaction that inserts a row into the table TBL_NAME_2 on another connection
+// to the given SQLite database, so the writes of other connections fail with "database is locked".
+// It returns the function that rolls back the transaction and releases the lock.
+func lockSqliteTable(t *testing.T, db *appdb.AppDb) func() {
+	locker := appdb.AppDb{Driver: db.Driver, Dsn: db.Dsn}
+	assert.Nil(t, locker.Open())
+	assert.Nil(t, locker.Begin())
+	_, err := locker.Exec(INSERT_INTO + TBL_NAME_2 + " VALUES (100, 'lock')")
+	assert.Nil(t, err)
+	return func() {
+		locker.Rollback()
+		locker.Close()
+	}
+}
+
+// TestWriteSqliteRetry verifies that a batch failed because the database is locked is written again
+// after the lock is released, also if it is the first batch of a transaction.
+func TestWriteSqliteRetry(t *testing.T) {
+	db := prepareSqliteDb(t, "dst.db")
+	unlock := lockSqliteTable(t, db)
+	retries := 0
+	p := DbProcessor{
+		AppDb:     db,
+		TableName: TBL_NAME_2,
+		Retry: appdb.RetryPolicy{
+			MaxAttempts: 3,
+			BaseDelay:   time.Millisecond,
+			OnRetry: func(attempt int, delay time.Duration, err error) {
+				retries++
+				unlock()
+			},
+		},
+	}
+	assert.Nil(t, p.Write([]string{INSERT_INTO + TBL_NAME_2 + " VALUES (?, ?)"}, []any{1, "a"}))
+	assert.Equal(t, 1, retries)
+	count, err := db.GetScalar("SELECT COUNT(*) FROM " + TBL_NAME_2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// The first batch of a transaction is written again in a new transaction
+	unlock = lockSqliteTable(t, db)
+	p.BatchesPerTransaction = 2
+	retries = 0
+	assert.Nil(t, p.Write([]string{INSERT_INTO + TBL_NAME_2 + " VALUES (?, ?)"}, []any{2, "b"}))
+	assert.Nil(t, p.Write([]string{INSERT_INTO + TBL_NAME_2 + " VALUES (?, ?)"}, []any{3, "c"}))
+	assert.Equal(t, 1, retries)
+	assert.False(t, p.InTransaction())
+	count, err = db.GetScalar("SELECT COUNT(*) FROM " + TBL_NAME_2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+// TestWriteSqliteRetryCancelled verifies that the batch waiting for a retry fails without waiting for the delay
+// when the context of the processing is done.
+func TestWriteSqliteRetryCancelled(t *testing.T) {
+	db := prepareSqliteDb(t, "dst.db")
+	unlock := lockSqliteTable(t, db)
+	defer unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	p := DbProcessor{
+		AppDb:     db,
+		TableName: TBL_NAME_2,
+		Retry: appdb.RetryPolicy{
+			MaxAttempts: 3,
+			BaseDelay:   time.Hour,
+			OnRetry:     func(attempt int, delay time.Duration, err error) { cancel() },
+		},
+	}
+	p.SetContext(ctx)
+	start := time.Now()
+	err := p.Write([]string{INSERT_INTO + TBL_NAME_2 + " VALUES (?, ?)"}, []any{1, "a"})
+	assert.ErrorContains(t, err, "locked")
+	assert.Less(t, time.Since(start), time.Minute)
+}
+
+// TestDbProcessorCanRetry verifies that a batch written in autocommit mode is not retried after the connection
+// is lost during the statement, as the server may have committed it, while the first batch of a transaction is.
+func TestDbProcessorCanRetry(t *testing.T) {
+	p := DbProcessor{AppDb: &appdb.AppDb{Driver: appdb.DRIVER_MYSQL}, Retry: appdb.RetryPolicy{MaxAttempts: 3}}
+	dialect := p.AppDb.GetDialect()
+	lost := fmt.Errorf("error writing to database: %w", &mysql.MySQLError{Number: 2013, Message: "Lost connection to server during query"})
+	deadlock := fmt.Errorf("error writing to database: %w", &mysql.MySQLError{Number: 1213})
+	assert.False(t, p.canRetry(1, dialect, lost))
+	assert.False(t, p.canRetry(1, dialect, fmt.Errorf("error writing to database: %w", mysql.ErrInvalidConn)))
+	assert.True(t, p.canRetry(1, dialect, deadlock))
+
+	p.BatchesPerTransaction = 2
+	assert.True(t, p.canRetry(1, dialect, lost))
+	p.batches = 1
+	assert.False(t, p.canRetry(1, dialect, deadlock))
+}
+
+// TestWriteSqliteDatasetTransaction verifies that with DatasetTransaction the batches are committed only by Commit,
+// are not visible to other connections before it, and are discarded by Rollback.
+func TestWriteSqliteDatasetTransaction(t *testing.T) {
+	db := prepareSqliteDb(t, "dst.db")
+	reader := appdb.AppDb{Driver: db.Driver, Dsn: db.Dsn}
+	assert.Nil(t, reader.Open())
+	defer reader.Close()
+	p := DbProcessor{AppDb: db, TableName: TBL_NAME_2, DatasetTransaction: true, BatchesPerTransaction: 1}
+	for id := 1; id <= 3; id++ {
+		assert.Nil(t, p.Write([]string{INSERT_INTO + TBL_NAME_2 + " VALUES (?, ?)"}, []any{id, "a"}))
+	}
+	assert.True(t, p.InTransaction())
+	count, err := reader.GetScalar("SELECT COUNT(*) FROM " + TBL_NAME_2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+	assert.Nil(t, p.Commit())
+	assert.False(t, p.InTransaction())
+
+	assert.Nil(t, p.Write([]string{INSERT_INTO + TBL_NAME_2 + " VALUES (?, ?)"}, []any{4, "b"}))
+	assert.Nil(t, p.Rollback())
+	count, err = reader.GetScalar("SELECT COUNT(*) FROM " + TBL_NAME_2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+}
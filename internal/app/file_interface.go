@@ -0,0 +1,35 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import "io"
+
+// FileInterface defines the contract for the output files of the file processors,
+// for example *os.File or a compressed appfile.AppFile.
+type FileInterface interface {
+	io.Writer
+
+	// Name returns the name of the file.
+	Name() string
+}
+
+// SplitFileInterface defines the contract for the output files of the parts of SplitProcessor.
+type SplitFileInterface interface {
+	FileInterface
+
+	// Size returns the size of the file on disk.
+	// It returns an error if the size cannot be read.
+	Size() (int64, error)
+
+	// Flush writes the buffered data to the file, so Size includes all written data.
+	// It returns an error if the data cannot be written.
+	Flush() error
+
+	// Sha256 returns the SHA-256 checksum in hex of the file content.
+	Sha256() string
+
+	// Close completes and closes the file.
+	// It returns an error if the file cannot be closed.
+	Close() error
+}
@@ -5,14 +5,14 @@ package app
 
 import (
 	"fmt"
-	"os"
+	"io"
 )
 
 // FileProcessor represents a file processing utility that manages file operations.
 // It contains a reference to an open file for writing or processing.
 type FileProcessor struct {
 	// Reference to an open file for writing or processing.
-	File *os.File
+	File FileInterface
 }
 
 // Write writes the given buffer of strings to the file, appending a newline
@@ -23,7 +23,7 @@ func (fp *FileProcessor) Write(buffer []string, data []any) error {
 		return fmt.Errorf("file is not set")
 	}
 	for _, stmt := range buffer {
-		_, err := fp.File.WriteString(stmt + "\n")
+		_, err := io.WriteString(fp.File, stmt+"\n")
 		if err != nil {
 			return err
 		}
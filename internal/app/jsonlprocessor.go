@@ -0,0 +1,103 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"bufio"
+	"copysqldatatool/internal/appdb"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// JsonlProcessor writes rows to a JSON Lines file.
+// Each row is written as one JSON object keyed by column name, in the order of the columns.
+type JsonlProcessor struct {
+	// Reference to an open file for writing.
+	File FileInterface
+}
+
+// Write is not supported by JsonlProcessor, as it writes row values instead of SQL statements.
+// See: app.RowsProcessorInterface.Write, app.RowsWriterInterface.WriteRows
+func (jp *JsonlProcessor) Write(buffer []string, data []any) error {
+	return fmt.Errorf("jsonl processor does not write SQL statements")
+}
+
+// WriteRows writes the given rows to the file, one JSON object per line.
+// The written lines are flushed to the file before the method returns.
+// See: app.RowsWriterInterface.WriteRows
+func (jp *JsonlProcessor) WriteRows(columns []appdb.Column, rows [][]any) error {
+	if jp.File == nil {
+		return fmt.Errorf("file is not set")
+	}
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		key, err := json.Marshal(column.Name)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+	writer := bufio.NewWriter(jp.File)
+	for _, row := range rows {
+		writer.WriteByte('{')
+		for i, val := range row {
+			if i > 0 {
+				writer.WriteByte(',')
+			}
+			writer.Write(keys[i])
+			writer.WriteByte(':')
+			value, err := jp.FormatValue(columns[i], val)
+			if err != nil {
+				return fmt.Errorf("error formatting column %s: %w", columns[i].Name, err)
+			}
+			writer.Write(value)
+		}
+		writer.WriteString("}\n")
+	}
+	return writer.Flush()
+}
+
+// FormatValue formats a value of the given column as a JSON value.
+// NULL values are formatted as null, dates in the RFC 3339 format.
+// Byte slices are formatted as numbers for numeric columns, as strings if they are valid UTF-8
+// and as base64 encoded strings otherwise.
+func (jp *JsonlProcessor) FormatValue(column appdb.Column, val any) ([]byte, error) {
+	switch v := val.(type) {
+	case nil:
+		return []byte("null"), nil
+	case []byte:
+		if column.IsNumeric() && isJsonNumber(v) {
+			return v, nil
+		}
+		if utf8.Valid(v) {
+			return json.Marshal(string(v))
+		}
+		return json.Marshal(base64.StdEncoding.EncodeToString(v))
+	case time.Time:
+		return json.Marshal(v.Format(time.RFC3339Nano))
+	default:
+		return json.Marshal(v)
+	}
+}
+
+// isJsonNumber returns true if the given bytes are a valid JSON number.
+func isJsonNumber(value []byte) bool {
+	if len(value) == 0 || (value[0] != '-' && (value[0] < '0' || value[0] > '9')) {
+		return false
+	}
+	return json.Valid(value)
+}
+
+// GetProcessedMsg returns a message indicating the number of rows processed
+// to the file specified by the File field.
+// See: app.RowsProcessorInterface.GetProcessedMsg
+func (jp *JsonlProcessor) GetProcessedMsg() string {
+	if jp.File == nil {
+		return fmt.Errorf("file is not set").Error()
+	}
+	return fmt.Sprint("Rows processed to file: ", jp.File.Name())
+}
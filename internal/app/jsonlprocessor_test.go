@@ -0,0 +1,68 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"copysqldatatool/internal/appdb"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestJsonlWriteRowsNilFile verifies that WriteRows returns an error when the File field is not set.
+func TestJsonlWriteRowsNilFile(t *testing.T) {
+	p := JsonlProcessor{}
+	err := p.WriteRows([]appdb.Column{{Name: "id"}}, [][]any{{1}})
+	assert.NotNil(t, err)
+}
+
+// TestJsonlFormatValue verifies that the values are mapped to the JSON types:
+// numbers stay numbers, NULL becomes null, byte slices become numbers, strings or base64 strings
+// and dates are formatted in the RFC 3339 format.
+func TestJsonlFormatValue(t *testing.T) {
+	p := JsonlProcessor{}
+	text := appdb.Column{Name: "name", DatabaseType: "VARCHAR"}
+	number := appdb.Column{Name: "price", DatabaseType: "DECIMAL"}
+	tests := []struct {
+		column   appdb.Column
+		value    any
+		expected string
+	}{
+		{text, nil, "null"},
+		{text, int64(10), "10"},
+		{text, 1.5, "1.5"},
+		{text, true, "true"},
+		{text, `a"b`, `"a\"b"`},
+		{text, []byte("text"), `"text"`},
+		{text, []byte{0xff, 0x00}, `"/wA="`},
+		{text, []byte("12.50"), `"12.50"`},
+		{number, []byte("12.50"), "12.50"},
+		{number, []byte("NaN"), `"NaN"`},
+		{text, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), `"2025-01-02T03:04:05Z"`},
+	}
+	for _, test := range tests {
+		actual, err := p.FormatValue(test.column, test.value)
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, string(actual))
+	}
+}
+
+// TestJsonlWriteRows verifies that each row is written as one JSON object keyed by column name.
+func TestJsonlWriteRows(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "test.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	p := JsonlProcessor{File: file}
+	columns := []appdb.Column{{Name: "id"}, {Name: "name"}}
+	err = p.WriteRows(columns, [][]any{{int64(1), "a"}, {int64(2), nil}})
+	assert.Nil(t, err)
+	data, err := os.ReadFile(file.Name())
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":null}\n", string(data))
+}
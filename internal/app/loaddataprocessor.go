@@ -0,0 +1,141 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"bufio"
+	"copysqldatatool/internal/appdb"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// loadDataReaders is the counter of the names of the readers registered for LOAD DATA LOCAL INFILE.
+var loadDataReaders atomic.Int64
+
+// LoadDataProcessor writes rows to a MySQL table with LOAD DATA LOCAL INFILE, which is much faster
+// than the multi-row INSERT statements. The rows of each batch are streamed to the server as TSV
+// by a reader registered with mysql.RegisterReaderHandler, so the batch is not formatted in memory.
+// The statements are executed by the embedded DbProcessor with its transactions, timeout and retry policy.
+type LoadDataProcessor struct {
+	// Processor of the destination connection executing the LOAD DATA statements.
+	*DbProcessor
+	// InsertCommand is the part of SQL command used for inserting data, for example "INSERT IGNORE INTO".
+	// IGNORE, REPLACE and LOW_PRIORITY are mapped to the modifiers of LOAD DATA.
+	InsertCommand string
+}
+
+// Write is not supported by LoadDataProcessor, as it writes row values instead of SQL statements.
+// See: app.RowsProcessorInterface.Write, app.RowsWriterInterface.WriteRows
+func (lp *LoadDataProcessor) Write(buffer []string, data []any) error {
+	return fmt.Errorf("load data processor does not write SQL statements")
+}
+
+// WriteRows loads the given rows with the given columns to the table with one LOAD DATA statement.
+// The rows are encoded again if the statement is retried.
+// See: app.RowsWriterInterface.WriteRows
+func (lp *LoadDataProcessor) WriteRows(columns []appdb.Column, rows [][]any) error {
+	if lp.DbProcessor == nil || lp.AppDb == nil {
+		return fmt.Errorf("db is not set")
+	}
+	query, err := lp.GetLoadDataStatement(columns)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("rows_%d", loadDataReaders.Add(1))
+	mysql.RegisterReaderHandler(name, func() io.Reader {
+		reader, writer := io.Pipe()
+		go func() {
+			writer.CloseWithError(WriteLoadDataRows(writer, rows))
+		}()
+		return reader
+	})
+	defer mysql.DeregisterReaderHandler(name)
+	return lp.execute(strings.ReplaceAll(query, "{{reader}}", name), nil)
+}
+
+// GetLoadDataStatement returns the LOAD DATA statement of the given columns with the placeholder {{reader}}
+// of the name of the reader. The modifiers of the statement are taken from InsertCommand:
+// "INSERT INTO" loads the rows without a modifier, "INSERT IGNORE INTO" with IGNORE and "REPLACE INTO" with REPLACE.
+// The statement uses the default format of LOAD DATA, which is TSV with the backslash escapes,
+// and the binary character set, so the values are loaded without conversion.
+// It returns an error if InsertCommand has a keyword that LOAD DATA does not support.
+func (lp *LoadDataProcessor) GetLoadDataStatement(columns []appdb.Column) (string, error) {
+	priority := ""
+	duplicates := ""
+	for _, keyword := range strings.Fields(strings.ToUpper(lp.InsertCommand)) {
+		switch keyword {
+		case "INSERT", "INTO":
+		case "IGNORE":
+			duplicates = " IGNORE"
+		case "REPLACE":
+			duplicates = " REPLACE"
+		case "LOW_PRIORITY":
+			priority = " LOW_PRIORITY"
+		default:
+			return "", fmt.Errorf("insert command is not supported by load data: %s", lp.InsertCommand)
+		}
+	}
+	dialect := lp.AppDb.GetDialect()
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = dialect.QuoteIdentifier(column.Name)
+	}
+	return fmt.Sprintf("LOAD DATA%s LOCAL INFILE 'Reader::{{reader}}'%s INTO TABLE %s CHARACTER SET binary (%s)",
+		priority, duplicates, lp.TableName, strings.Join(names, ", ")), nil
+}
+
+// WriteLoadDataRows writes the given rows to the given writer in the default format of LOAD DATA:
+// the fields are separated by tabs and the lines end with a newline. NULL values are written as \N,
+// and backslashes, tabs, newlines, carriage returns and zero bytes in the values are escaped with a backslash.
+// Booleans are written as 1 and 0.
+func WriteLoadDataRows(writer io.Writer, rows [][]any) error {
+	buffered := bufio.NewWriter(writer)
+	for _, row := range rows {
+		for i, val := range row {
+			if i > 0 {
+				buffered.WriteByte('\t')
+			}
+			writeLoadDataValue(buffered, val)
+		}
+		buffered.WriteByte('\n')
+	}
+	return buffered.Flush()
+}
+
+// writeLoadDataValue writes the given value escaped for LOAD DATA to the given writer.
+func writeLoadDataValue(writer *bufio.Writer, val any) {
+	var str string
+	switch v := val.(type) {
+	case nil:
+		writer.WriteString(`\N`)
+		return
+	case bool:
+		str = "0"
+		if v {
+			str = "1"
+		}
+	default:
+		str = formatTextValue(val)
+	}
+	for i := 0; i < len(str); i++ {
+		switch str[i] {
+		case '\\':
+			writer.WriteString(`\\`)
+		case '\t':
+			writer.WriteString(`\t`)
+		case '\n':
+			writer.WriteString(`\n`)
+		case '\r':
+			writer.WriteString(`\r`)
+		case 0:
+			writer.WriteString(`\0`)
+		default:
+			writer.WriteByte(str[i])
+		}
+	}
+}
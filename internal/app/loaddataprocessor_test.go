@@ -0,0 +1,58 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"bytes"
+	"copysqldatatool/internal/appdb"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWriteLoadDataRows verifies that the rows are written as TSV with NULL values written as \N,
+// the special characters escaped with a backslash and booleans written as 1 and 0.
+func TestWriteLoadDataRows(t *testing.T) {
+	var buffer bytes.Buffer
+	rows := [][]any{
+		{int64(1), "a\tb\nc\\d\re", nil, true},
+		{int64(2), []byte("x\x00y"), "", false},
+		{int64(3), time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC), `\N`, 1.5},
+	}
+	assert.Nil(t, WriteLoadDataRows(&buffer, rows))
+	expected := "1\ta\\tb\\nc\\\\d\\re\t\\N\t1\n" +
+		"2\tx\\0y\t\t0\n" +
+		"3\t2025-01-02 03:04:05.000006\t\\\\N\t1.5\n"
+	assert.Equal(t, expected, buffer.String())
+}
+
+// TestGetLoadDataStatement verifies that the modifiers of the insert command are mapped
+// to the modifiers of LOAD DATA and that the unsupported insert commands fail.
+func TestGetLoadDataStatement(t *testing.T) {
+	columns := []appdb.Column{{Name: "id"}, {Name: "na`me"}}
+	lp := LoadDataProcessor{DbProcessor: &DbProcessor{AppDb: &appdb.AppDb{Driver: appdb.DRIVER_MYSQL}, TableName: TBL_NAME}}
+	for command, expected := range map[string]string{
+		"INSERT INTO":              "LOAD DATA LOCAL INFILE 'Reader::{{reader}}' INTO TABLE ",
+		"insert ignore into":       "LOAD DATA LOCAL INFILE 'Reader::{{reader}}' IGNORE INTO TABLE ",
+		"REPLACE INTO":             "LOAD DATA LOCAL INFILE 'Reader::{{reader}}' REPLACE INTO TABLE ",
+		"INSERT LOW_PRIORITY INTO": "LOAD DATA LOW_PRIORITY LOCAL INFILE 'Reader::{{reader}}' INTO TABLE ",
+	} {
+		lp.InsertCommand = command
+		statement, err := lp.GetLoadDataStatement(columns)
+		assert.Nil(t, err, command)
+		assert.Equal(t, expected+TBL_NAME+" CHARACTER SET binary (`id`, `na``me`)", statement, command)
+	}
+	lp.InsertCommand = "INSERT DELAYED INTO"
+	_, err := lp.GetLoadDataStatement(columns)
+	assert.ErrorContains(t, err, "not supported")
+}
+
+// TestLoadDataProcessorWrite verifies that the LoadDataProcessor does not write SQL statements
+// and fails to write the rows without the database.
+func TestLoadDataProcessorWrite(t *testing.T) {
+	lp := LoadDataProcessor{}
+	assert.Error(t, lp.Write([]string{INSERT_3}, nil))
+	assert.ErrorContains(t, lp.WriteRows([]appdb.Column{{Name: "id"}}, [][]any{{1}}), "db is not set")
+}
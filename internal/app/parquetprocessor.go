@@ -0,0 +1,333 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"copysqldatatool/internal/appdb"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Constants. Parquet column kinds and defaults.
+const (
+	PARQUET_KIND_STRING = iota
+	PARQUET_KIND_BYTES
+	PARQUET_KIND_BOOLEAN
+	PARQUET_KIND_INT64
+	PARQUET_KIND_UINT64
+	PARQUET_KIND_DOUBLE
+	PARQUET_KIND_DECIMAL64
+	PARQUET_KIND_DECIMAL128
+	PARQUET_KIND_DATE
+	PARQUET_KIND_TIMESTAMP
+	// Default number of rows in one row group
+	PARQUET_ROW_GROUP_SIZE = 100000
+	// Max precision of decimals stored as INT64
+	PARQUET_DECIMAL64_PRECISION = 18
+	// Max precision of decimals stored as FIXED_LEN_BYTE_ARRAY(16)
+	PARQUET_DECIMAL128_PRECISION = 38
+	// Size of decimals stored as FIXED_LEN_BYTE_ARRAY
+	PARQUET_DECIMAL128_SIZE = 16
+)
+
+// Layouts used to parse the dates returned by drivers as text, for example MySQL without parseTime.
+var parquetTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	time.DateOnly,
+}
+
+// parquetColumn describes how the values of a source column are written to the Parquet file.
+type parquetColumn struct {
+	// Source column
+	column appdb.Column
+	// Parquet column kind, see PARQUET_KIND_* constants
+	kind int
+	// Index of the leaf column in the Parquet schema
+	index int
+	// Definition level of non-NULL values
+	definitionLevel int
+}
+
+// ParquetProcessor writes rows to an Apache Parquet file.
+// The Parquet schema is built from the columns of the first written rows.
+// Nullable columns are written as optional columns, decimals and dates are written with the
+// DECIMAL, DATE and TIMESTAMP logical types. Other columns are written as strings or binary data.
+// The file footer is written by Finish, the file is not valid before it is called.
+type ParquetProcessor struct {
+	// Reference to an open file for writing.
+	File FileInterface
+	// Number of rows in one row group. If 0, PARQUET_ROW_GROUP_SIZE is used.
+	RowGroupSize int64
+	// Parquet writer of the file.
+	writer *parquet.Writer
+	// Parquet columns in the order of the source columns.
+	columns []parquetColumn
+}
+
+// Write is not supported by ParquetProcessor, as it writes row values instead of SQL statements.
+// See: app.RowsProcessorInterface.Write, app.RowsWriterInterface.WriteRows
+func (pp *ParquetProcessor) Write(buffer []string, data []any) error {
+	return fmt.Errorf("parquet processor does not write SQL statements")
+}
+
+// WriteRows writes the given rows to the Parquet file.
+// The Parquet writer is created with the first rows using the schema built from the given columns.
+// See: app.RowsWriterInterface.WriteRows
+func (pp *ParquetProcessor) WriteRows(columns []appdb.Column, rows [][]any) error {
+	if pp.File == nil {
+		return fmt.Errorf("file is not set")
+	}
+	if pp.writer == nil {
+		if err := pp.createWriter(columns); err != nil {
+			return err
+		}
+	}
+	parquetRows := make([]parquet.Row, len(rows))
+	for i, row := range rows {
+		parquetRow := make(parquet.Row, len(pp.columns))
+		for j, val := range row {
+			column := pp.columns[j]
+			value, err := pp.convertValue(column, val)
+			if err != nil {
+				return fmt.Errorf("error converting column %s: %w", column.column.Name, err)
+			}
+			parquetRow[column.index] = value
+		}
+		parquetRows[i] = parquetRow
+	}
+	_, err := pp.writer.WriteRows(parquetRows)
+	return err
+}
+
+// Finish flushes the buffered rows and writes the footer of the Parquet file.
+// See: app.RowsProcessorFinishInterface.Finish
+func (pp *ParquetProcessor) Finish() error {
+	if pp.writer == nil {
+		return nil
+	}
+	return pp.writer.Close()
+}
+
+// createWriter builds the Parquet schema from the given columns and creates the Parquet writer.
+// It returns an error if the column names are not unique.
+func (pp *ParquetProcessor) createWriter(columns []appdb.Column) error {
+	group := parquet.Group{}
+	kinds := make([]int, len(columns))
+	for i, column := range columns {
+		if _, ok := group[column.Name]; ok {
+			return fmt.Errorf("duplicate column name: %s", column.Name)
+		}
+		kinds[i] = getParquetKind(column)
+		node := createParquetNode(column, kinds[i])
+		if column.Nullable {
+			node = parquet.Optional(node)
+		}
+		group[column.Name] = node
+	}
+	schema := parquet.NewSchema("schema", group)
+	pp.columns = make([]parquetColumn, len(columns))
+	for i, column := range columns {
+		leaf, _ := schema.Lookup(column.Name)
+		pp.columns[i] = parquetColumn{
+			column:          column,
+			kind:            kinds[i],
+			index:           leaf.ColumnIndex,
+			definitionLevel: leaf.MaxDefinitionLevel,
+		}
+	}
+	rowGroupSize := pp.RowGroupSize
+	if rowGroupSize <= 0 {
+		rowGroupSize = PARQUET_ROW_GROUP_SIZE
+	}
+	config, err := parquet.NewWriterConfig(schema, parquet.MaxRowsPerRowGroup(rowGroupSize))
+	if err != nil {
+		return err
+	}
+	pp.writer = parquet.NewWriter(pp.File, config)
+	return nil
+}
+
+// getParquetKind returns the Parquet column kind for the database type of the given column.
+// See: PARQUET_KIND_* constants
+func getParquetKind(column appdb.Column) int {
+	switch column.BaseType() {
+	case "BOOL", "BOOLEAN":
+		return PARQUET_KIND_BOOLEAN
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "UINT":
+		if column.IsUnsigned() {
+			return PARQUET_KIND_UINT64
+		}
+		return PARQUET_KIND_INT64
+	case "FLOAT", "DOUBLE", "REAL":
+		return PARQUET_KIND_DOUBLE
+	case "DECIMAL", "NUMERIC":
+		if column.Precision > 0 && column.Precision <= PARQUET_DECIMAL64_PRECISION {
+			return PARQUET_KIND_DECIMAL64
+		}
+		if column.Precision > 0 && column.Precision <= PARQUET_DECIMAL128_PRECISION {
+			return PARQUET_KIND_DECIMAL128
+		}
+		return PARQUET_KIND_STRING
+	case "DATE":
+		return PARQUET_KIND_DATE
+	case "DATETIME", "TIMESTAMP", "TIMESTAMPTZ":
+		return PARQUET_KIND_TIMESTAMP
+	case "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY", "BYTEA":
+		return PARQUET_KIND_BYTES
+	default:
+		return PARQUET_KIND_STRING
+	}
+}
+
+// createParquetNode returns the Parquet schema node for the given column and column kind.
+func createParquetNode(column appdb.Column, kind int) parquet.Node {
+	switch kind {
+	case PARQUET_KIND_BOOLEAN:
+		return parquet.Leaf(parquet.BooleanType)
+	case PARQUET_KIND_INT64:
+		return parquet.Int(64)
+	case PARQUET_KIND_UINT64:
+		return parquet.Uint(64)
+	case PARQUET_KIND_DOUBLE:
+		return parquet.Leaf(parquet.DoubleType)
+	case PARQUET_KIND_DECIMAL64:
+		return parquet.Decimal(int(column.Scale), int(column.Precision), parquet.Int64Type)
+	case PARQUET_KIND_DECIMAL128:
+		return parquet.Decimal(int(column.Scale), int(column.Precision), parquet.FixedLenByteArrayType(PARQUET_DECIMAL128_SIZE))
+	case PARQUET_KIND_DATE:
+		return parquet.Date()
+	case PARQUET_KIND_TIMESTAMP:
+		return parquet.TimestampAdjusted(parquet.Microsecond, column.BaseType() == "TIMESTAMPTZ")
+	case PARQUET_KIND_BYTES:
+		return parquet.Leaf(parquet.ByteArrayType)
+	default:
+		return parquet.String()
+	}
+}
+
+// convertValue converts a value of the given column to the Parquet value of the column kind.
+// It returns an error if the value cannot be converted or if it is NULL and the column is not nullable.
+func (pp *ParquetProcessor) convertValue(column parquetColumn, val any) (parquet.Value, error) {
+	if val == nil {
+		if !column.column.Nullable {
+			return parquet.Value{}, fmt.Errorf("NULL value in not nullable column")
+		}
+		return parquet.NullValue().Level(0, 0, column.index), nil
+	}
+	var value parquet.Value
+	var err error
+	switch column.kind {
+	case PARQUET_KIND_BOOLEAN:
+		var b bool
+		b, err = toParquetBool(val)
+		value = parquet.BooleanValue(b)
+	case PARQUET_KIND_INT64:
+		var i int64
+		i, err = strconv.ParseInt(formatTextValue(val), 10, 64)
+		value = parquet.Int64Value(i)
+	case PARQUET_KIND_UINT64:
+		var u uint64
+		u, err = strconv.ParseUint(formatTextValue(val), 10, 64)
+		value = parquet.Int64Value(int64(u))
+	case PARQUET_KIND_DOUBLE:
+		var f float64
+		f, err = strconv.ParseFloat(formatTextValue(val), 64)
+		value = parquet.DoubleValue(f)
+	case PARQUET_KIND_DECIMAL64, PARQUET_KIND_DECIMAL128:
+		value, err = toParquetDecimal(formatTextValue(val), column)
+	case PARQUET_KIND_DATE:
+		var t time.Time
+		t, err = toParquetTime(val)
+		value = parquet.Int32Value(int32(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400))
+	case PARQUET_KIND_TIMESTAMP:
+		var t time.Time
+		t, err = toParquetTime(val)
+		value = parquet.Int64Value(t.UnixMicro())
+	case PARQUET_KIND_BYTES:
+		if b, ok := val.([]byte); ok {
+			value = parquet.ByteArrayValue(b)
+		} else {
+			value = parquet.ByteArrayValue([]byte(formatTextValue(val)))
+		}
+	default:
+		value = parquet.ByteArrayValue([]byte(formatTextValue(val)))
+	}
+	if err != nil {
+		return parquet.Value{}, err
+	}
+	return value.Level(0, column.definitionLevel, column.index), nil
+}
+
+// toParquetBool converts a boolean or a number to a boolean.
+func toParquetBool(val any) (bool, error) {
+	if b, ok := val.(bool); ok {
+		return b, nil
+	}
+	str := formatTextValue(val)
+	if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+		return i != 0, nil
+	}
+	return strconv.ParseBool(str)
+}
+
+// toParquetTime converts a date or a date string to time.Time.
+// The date strings without time zone are parsed in UTC.
+func toParquetTime(val any) (time.Time, error) {
+	if t, ok := val.(time.Time); ok {
+		return t, nil
+	}
+	str := formatTextValue(val)
+	for _, layout := range parquetTimeLayouts {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %s", str)
+}
+
+// toParquetDecimal converts a decimal string to the unscaled Parquet decimal value of the column,
+// INT64 for the precision up to 18 digits and 16 bytes two's complement big-endian number otherwise.
+// Digits after the scale of the column are truncated.
+func toParquetDecimal(str string, column parquetColumn) (parquet.Value, error) {
+	scale := int(column.column.Scale)
+	intPart, fracPart, _ := strings.Cut(strings.TrimSpace(str), ".")
+	if len(fracPart) > scale {
+		fracPart = fracPart[:scale]
+	}
+	fracPart += strings.Repeat("0", scale-len(fracPart))
+	unscaled, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return parquet.Value{}, fmt.Errorf("invalid decimal: %s", str)
+	}
+	if column.kind == PARQUET_KIND_DECIMAL64 {
+		if !unscaled.IsInt64() {
+			return parquet.Value{}, fmt.Errorf("decimal out of range: %s", str)
+		}
+		return parquet.Int64Value(unscaled.Int64()), nil
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), PARQUET_DECIMAL128_SIZE*8-1)
+	if unscaled.CmpAbs(limit) >= 0 {
+		return parquet.Value{}, fmt.Errorf("decimal out of range: %s", str)
+	}
+	if unscaled.Sign() < 0 {
+		unscaled.Add(unscaled, new(big.Int).Lsh(big.NewInt(1), PARQUET_DECIMAL128_SIZE*8))
+	}
+	return parquet.FixedLenByteArrayValue(unscaled.FillBytes(make([]byte, PARQUET_DECIMAL128_SIZE))), nil
+}
+
+// GetProcessedMsg returns a message indicating the number of rows processed
+// to the file specified by the File field.
+// See: app.RowsProcessorInterface.GetProcessedMsg
+func (pp *ParquetProcessor) GetProcessedMsg() string {
+	if pp.File == nil {
+		return fmt.Errorf("file is not set").Error()
+	}
+	return fmt.Sprint("Rows processed to file: ", pp.File.Name())
+}
@@ -0,0 +1,127 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"copysqldatatool/internal/appdb"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// readParquetFile opens the Parquet file with the given name and reads all rows of it.
+func readParquetFile(t *testing.T, fileName string) (*parquet.File, []parquet.Row) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	info, err := file.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parquetFile, err := parquet.OpenFile(file, info.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := []parquet.Row{}
+	for _, rowGroup := range parquetFile.RowGroups() {
+		reader := rowGroup.Rows()
+		buffer := make([]parquet.Row, rowGroup.NumRows())
+		n, err := reader.ReadRows(buffer)
+		if err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		rows = append(rows, buffer[:n]...)
+		reader.Close()
+	}
+	return parquetFile, rows
+}
+
+// TestParquetWriteRows verifies that the Parquet schema is built from the columns with optional nullable columns
+// and DECIMAL, DATE and TIMESTAMP logical types, the rows are written in row groups of the given size
+// and the file is valid after Finish.
+func TestParquetWriteRows(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "test.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	p := ParquetProcessor{File: file, RowGroupSize: 2}
+	columns := []appdb.Column{
+		{Name: "id", DatabaseType: "BIGINT"},
+		{Name: "name", DatabaseType: "VARCHAR", Nullable: true},
+		{Name: "price", DatabaseType: "DECIMAL", Precision: 10, Scale: 2, Nullable: true},
+		{Name: "big", DatabaseType: "DECIMAL", Precision: 30, Scale: 2},
+		{Name: "created", DatabaseType: "DATETIME"},
+		{Name: "day", DatabaseType: "DATE"},
+	}
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	err = p.WriteRows(columns, [][]any{
+		{int64(1), "a", []byte("12.5"), []byte("-1.05"), created, created},
+		{[]byte("2"), nil, nil, "100", []byte("2025-01-02 03:04:05"), []byte("2025-01-02")},
+	})
+	assert.Nil(t, err)
+	err = p.WriteRows(columns, [][]any{{int64(3), "c", "0.01", "0", created, created}})
+	assert.Nil(t, err)
+	err = p.Finish()
+	assert.Nil(t, err)
+
+	parquetFile, rows := readParquetFile(t, file.Name())
+	assert.Equal(t, int64(3), parquetFile.NumRows())
+	assert.Len(t, parquetFile.RowGroups(), 2)
+	schema := parquetFile.Schema()
+	name, _ := schema.Lookup("name")
+	assert.True(t, name.Node.Optional())
+	id, _ := schema.Lookup("id")
+	assert.True(t, id.Node.Required())
+	price, _ := schema.Lookup("price")
+	assert.NotNil(t, price.Node.Type().LogicalType().Decimal)
+	big, _ := schema.Lookup("big")
+	assert.NotNil(t, big.Node.Type().LogicalType().Decimal)
+	createdColumn, _ := schema.Lookup("created")
+	assert.NotNil(t, createdColumn.Node.Type().LogicalType().Timestamp)
+	day, _ := schema.Lookup("day")
+	assert.NotNil(t, day.Node.Type().LogicalType().Date)
+
+	if assert.Len(t, rows, 3) {
+		assert.Equal(t, int64(1), rows[0][id.ColumnIndex].Int64())
+		assert.Equal(t, "a", rows[0][name.ColumnIndex].String())
+		assert.Equal(t, int64(1250), rows[0][price.ColumnIndex].Int64())
+		assert.Equal(t, created.UnixMicro(), rows[0][createdColumn.ColumnIndex].Int64())
+		assert.Equal(t, int32(created.Unix()/86400), rows[0][day.ColumnIndex].Int32())
+		assert.Equal(t, int64(2), rows[1][id.ColumnIndex].Int64())
+		assert.True(t, rows[1][name.ColumnIndex].IsNull())
+		assert.True(t, rows[1][price.ColumnIndex].IsNull())
+		assert.Equal(t, created.UnixMicro(), rows[1][createdColumn.ColumnIndex].Int64())
+		assert.Equal(t, int64(1), rows[2][price.ColumnIndex].Int64())
+	}
+}
+
+// TestParquetWriteRowsNotNullable verifies that WriteRows returns an error for NULL values in not nullable columns.
+func TestParquetWriteRowsNotNullable(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "test.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	p := ParquetProcessor{File: file}
+	err = p.WriteRows([]appdb.Column{{Name: "id", DatabaseType: "INT"}}, [][]any{{nil}})
+	assert.NotNil(t, err)
+}
+
+// TestParquetDecimal128 verifies that the decimals with the precision over 18 digits are written as
+// 16 bytes two's complement big-endian numbers.
+func TestParquetDecimal128(t *testing.T) {
+	column := parquetColumn{column: appdb.Column{Scale: 2}, kind: PARQUET_KIND_DECIMAL128}
+	value, err := toParquetDecimal("-1.05", column)
+	assert.Nil(t, err)
+	expected := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x97}
+	assert.Equal(t, expected, value.ByteArray())
+}
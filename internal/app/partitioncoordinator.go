@@ -0,0 +1,110 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"context"
+	"copysqldatatool/internal/applog"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// PartitionCoordinator processes the partitions of a dataset in parallel, one goroutine per partition.
+// Each partition is a RowsProcessor with its own data reader and processors.
+// The progress of all partitions is merged into one log message after each written batch.
+// If any partition fails, the other partitions are cancelled and their uncommitted rows
+// are rolled back, so the dataset fails as a whole.
+type PartitionCoordinator struct {
+	// Processors of the partitions.
+	Partitions []*RowsProcessor
+	// Log for recording the merged progress.
+	Log *applog.AppLog
+	// Number of rows written by each partition.
+	rows []int64
+	// Errors of the partitions returned by the last call of Process.
+	errs []error
+	// mutex is used to synchronize access to the rows of the partitions.
+	mutex sync.Mutex
+}
+
+// Process processes all partitions in parallel and waits for them to complete.
+// It returns an error that joins the errors of the failed partitions, the partitions
+// cancelled because of another partition's failure are not reported.
+// If the given context is done, all partitions are cancelled and the returned error wraps ErrCancelled.
+func (pc *PartitionCoordinator) Process(ctx context.Context) error {
+	pc.rows = make([]int64, len(pc.Partitions))
+	partitionsCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pc.errs = make([]error, len(pc.Partitions))
+	errs := pc.errs
+	var wg sync.WaitGroup
+	for i, partition := range pc.Partitions {
+		partition.OnBatchWritten.Subscribe(func(data any) {
+			pc.setRows(i, data.(BatchProgress).Rows, false)
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := partition.Process(partitionsCtx)
+			pc.setRows(i, partition.GetRowsCount(), true)
+			if err != nil {
+				errs[i] = err
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	failed := make([]error, 0, len(errs))
+	cancelled := false
+	for i, err := range errs {
+		if errors.Is(err, ErrCancelled) {
+			cancelled = true
+		} else if err != nil {
+			failed = append(failed, fmt.Errorf("partition %d: %w", i+1, err))
+		}
+	}
+	if len(failed) == 0 && cancelled {
+		return fmt.Errorf("%w: %w", ErrCancelled, context.Cause(ctx))
+	}
+	return errors.Join(failed...)
+}
+
+// GetError returns the error of the partition with the given index returned by the last call of Process,
+// or nil if the partition has completed.
+func (pc *PartitionCoordinator) GetError(index int) error {
+	if index < 0 || index >= len(pc.errs) {
+		return nil
+	}
+	return pc.errs[index]
+}
+
+// setRows sets the number of rows written by the partition with the given index
+// and logs the total number of rows written by all partitions.
+func (pc *PartitionCoordinator) setRows(index int, rows int64, completed bool) {
+	pc.mutex.Lock()
+	pc.rows[index] = rows
+	pc.mutex.Unlock()
+	if pc.Log == nil {
+		return
+	}
+	total := pc.GetRowsCount()
+	if completed {
+		pc.Log.Info("Partition", index+1, "of", len(pc.Partitions), "ended. Rows processed by all partitions:", total)
+	} else {
+		pc.Log.Info("Rows processed by all partitions...:", total)
+	}
+}
+
+// GetRowsCount returns the number of rows processed by all partitions.
+func (pc *PartitionCoordinator) GetRowsCount() int64 {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+	total := int64(0)
+	for _, rows := range pc.rows {
+		total += rows
+	}
+	return total
+}
@@ -0,0 +1,59 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPartitionCoordinator verifies that the PartitionCoordinator processes all partitions
+// and merges the number of processed rows.
+func TestPartitionCoordinator(t *testing.T) {
+	src := prepareSqliteDb(t, "src.db")
+	coordinator := PartitionCoordinator{Partitions: []*RowsProcessor{
+		prepareSqliteProcessor(src, &testProcessor{}, 1),
+		prepareSqliteProcessor(src, &testProcessor{}, 2),
+	}}
+	assert.NoError(t, coordinator.Process(context.Background()))
+	assert.Equal(t, int64(6), coordinator.GetRowsCount())
+}
+
+// TestPartitionCoordinatorFailure verifies that the PartitionCoordinator cancels the other partitions
+// when a partition fails and returns only the error of the failed partition.
+func TestPartitionCoordinatorFailure(t *testing.T) {
+	src := prepareSqliteDb(t, "src.db")
+	coordinator := PartitionCoordinator{Partitions: []*RowsProcessor{
+		prepareSqliteProcessor(src, &testProcessor{}, 1),
+		prepareSqliteProcessor(src, &testProcessor{err: fmt.Errorf("write error")}, 1),
+	}}
+	err := coordinator.Process(context.Background())
+	assert.ErrorContains(t, err, "partition 2:")
+	assert.ErrorContains(t, err, "write error")
+	assert.NotContains(t, err.Error(), "partition 1:")
+	assert.NotErrorIs(t, err, ErrCancelled)
+	assert.Error(t, coordinator.GetError(1))
+}
+
+// TestPartitionCoordinatorCancel verifies that the PartitionCoordinator cancels all partitions
+// when the context is done and returns ErrCancelled.
+func TestPartitionCoordinatorCancel(t *testing.T) {
+	src := prepareSqliteDb(t, "src.db")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	processors := []*testProcessor{{}, {}}
+	coordinator := PartitionCoordinator{Partitions: []*RowsProcessor{
+		prepareSqliteProcessor(src, processors[0], 1),
+		prepareSqliteProcessor(src, processors[1], 1),
+	}}
+	err := coordinator.Process(ctx)
+	assert.ErrorIs(t, err, ErrCancelled)
+	assert.NotContains(t, err.Error(), "partition")
+	assert.Empty(t, processors[0].buffers)
+	assert.Empty(t, processors[1].buffers)
+	assert.ErrorIs(t, coordinator.GetError(0), ErrCancelled)
+}
@@ -0,0 +1,123 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"bufio"
+	"copysqldatatool/internal/appdb"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrTooManyRejects is returned by RejectProcessor.WriteReject when the number of rejected rows exceeds MaxRejects.
+var ErrTooManyRejects = errors.New("too many rejected rows")
+
+// RejectProcessor writes the rows rejected by the destination to a JSON Lines file.
+// Each line is a JSON object with the error message of the destination and the row keyed by column name,
+// for example {"error":"Data too long for column 'name'","row":{"id":1,"name":"..."}}.
+// The file is created when the first row is rejected, so no file is left if all rows are written.
+// It is safe for concurrent use, so the partitions of a dataset can share it.
+type RejectProcessor struct {
+	// Path of the file.
+	Path string
+	// Append the rows to the existing file instead of truncating it, for example when the dataset is resumed.
+	Append bool
+	// Max number of rejected rows. If 0, the number of rejected rows is not limited.
+	MaxRejects int64
+	// Number of written rows.
+	count int64
+	// mutex is used to synchronize the writes of the partitions.
+	mutex sync.Mutex
+	// Open file, nil until the first row is rejected.
+	file *os.File
+	// Formatter of the row values.
+	jsonl JsonlProcessor
+}
+
+// WriteReject writes the given row and the error it was rejected with to the file.
+// It returns ErrTooManyRejects if the row exceeds MaxRejects, the row is not written in this case.
+// See: app.RejectWriterInterface.WriteReject
+func (rp *RejectProcessor) WriteReject(columns []appdb.Column, row []any, reason error) error {
+	rp.mutex.Lock()
+	defer rp.mutex.Unlock()
+	if rp.MaxRejects > 0 && rp.count >= rp.MaxRejects {
+		return fmt.Errorf("%w: %d: %w", ErrTooManyRejects, rp.MaxRejects, reason)
+	}
+	if err := rp.open(); err != nil {
+		return err
+	}
+	message, err := json.Marshal(reason.Error())
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(rp.file)
+	writer.WriteString(`{"error":`)
+	writer.Write(message)
+	writer.WriteString(`,"row":{`)
+	for i, val := range row {
+		if i > 0 {
+			writer.WriteByte(',')
+		}
+		key, err := json.Marshal(columns[i].Name)
+		if err != nil {
+			return err
+		}
+		writer.Write(key)
+		writer.WriteByte(':')
+		value, err := rp.jsonl.FormatValue(columns[i], val)
+		if err != nil {
+			return fmt.Errorf("error formatting column %s: %w", columns[i].Name, err)
+		}
+		writer.Write(value)
+	}
+	writer.WriteString("}}\n")
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("error writing rejected row: %w", err)
+	}
+	rp.count++
+	return nil
+}
+
+// GetCount returns the number of rows written to the file.
+func (rp *RejectProcessor) GetCount() int64 {
+	rp.mutex.Lock()
+	defer rp.mutex.Unlock()
+	return rp.count
+}
+
+// open creates the file and its directory if the file is not open yet.
+func (rp *RejectProcessor) open() error {
+	if rp.file != nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(rp.Path), 0755); err != nil {
+		return err
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if rp.Append {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(rp.Path, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("error creating file of rejected rows: %w", err)
+	}
+	rp.file = file
+	return nil
+}
+
+// Close closes the file if it has been created.
+func (rp *RejectProcessor) Close() error {
+	rp.mutex.Lock()
+	defer rp.mutex.Unlock()
+	if rp.file == nil {
+		return nil
+	}
+	err := rp.file.Close()
+	rp.file = nil
+	return err
+}
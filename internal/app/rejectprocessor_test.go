@@ -0,0 +1,43 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"copysqldatatool/internal/appdb"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRejectProcessor verifies that the rejected rows are written with the error message as JSON lines,
+// that the file is created only for the first rejected row and that MaxRejects limits the number of rows.
+func TestRejectProcessor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "test.rejected.jsonl")
+	columns := []appdb.Column{{Name: "id", DatabaseType: "INTEGER"}, {Name: "name", DatabaseType: "TEXT"}}
+	p := RejectProcessor{Path: path, MaxRejects: 2}
+	assert.Nil(t, p.Close())
+	assert.NoFileExists(t, path)
+
+	assert.Nil(t, p.WriteReject(columns, []any{int64(1), nil}, errors.New(`NOT NULL constraint failed: "name"`)))
+	assert.Nil(t, p.WriteReject(columns, []any{int64(2), []byte("b")}, errors.New("error")))
+	err := p.WriteReject(columns, []any{int64(3), "c"}, errors.New("error"))
+	assert.ErrorIs(t, err, ErrTooManyRejects)
+	assert.Equal(t, int64(2), p.GetCount())
+	assert.Nil(t, p.Close())
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"error":"NOT NULL constraint failed: \"name\"","row":{"id":1,"name":null}}`+"\n"+
+		`{"error":"error","row":{"id":2,"name":"b"}}`+"\n", string(data))
+
+	p = RejectProcessor{Path: path, Append: true}
+	assert.Nil(t, p.WriteReject(columns, []any{int64(3), "c"}, errors.New("error")))
+	assert.Nil(t, p.Close())
+	data, err = os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `{"id":1,"name":null}`)
+	assert.Contains(t, string(data), `{"id":3,"name":"c"}`)
+}
@@ -0,0 +1,340 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"context"
+	"copysqldatatool/internal/appbuffer"
+	"copysqldatatool/internal/appdb"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Constants for the pipeline.
+const (
+	// Number of batches formatted ahead of the writer if the pipeline depth is not set
+	PIPELINE_DEPTH = 2
+	// Number of rows read ahead of the formatter
+	PIPELINE_ROWS_BUFFER = 1024
+)
+
+// rowsBatch is a batch of rows formatted for the processors.
+type rowsBatch struct {
+	// INSERT statement of the rows for the processors that write SQL statements.
+	buffer *appbuffer.AppBuffer
+	// Values of the prepared statement.
+	data []any
+	// Rows for the processors that write the row values.
+	rows [][]any
+	// Number of rows in the batch.
+	count int64
+	// Number of rows processed up to the end of the batch.
+	rowsCount int64
+	// Position of the data reader after the last row of the batch.
+	state appdb.ReaderState
+	// True if the batch has the current rows per command, so its latency adjusts the rows with AdaptiveRows.
+	full bool
+	// True if the batch is the last batch of the rows.
+	last bool
+	// Sequence number of the batch, the batches are numbered from 0 in the order of the rows.
+	seq int64
+	// Time of writing the batch by a parallel writer.
+	latency time.Duration
+}
+
+// checkpoint tracks the batches written by the parallel writers, so the position is reported
+// only after all earlier batches have been written.
+type checkpoint struct {
+	// mutex is used to synchronize the writers.
+	mutex sync.Mutex
+	// Sequence number of the first batch that has not been written yet.
+	next int64
+	// Written batches after the first batch that has not been written yet by sequence number.
+	done map[int64]*rowsBatch
+}
+
+// pipelineRow is a row read by the reader of the pipeline.
+type pipelineRow struct {
+	// Copy of the row values.
+	values []any
+	// Position of the data reader after the row.
+	state appdb.ReaderState
+}
+
+// processPipeline processes the rows in three goroutines connected by bounded channels:
+// the reader reads the rows from the data reader, the formatter builds the batches and the writer
+// writes them to the processors. The reader keeps reading while a batch is being written, so the
+// query of the next chunk is executed on the source database while the destination is busy.
+// Up to PipelineDepth batches and PIPELINE_ROWS_BUFFER rows are read ahead of the writer.
+// The batches are written and reported in order. If any stage fails, the other stages are stopped
+// and the error of the first failed stage is returned.
+// If Writers are set, each writer writes the batches in its own goroutine.
+// See: RowsProcessor.writeParallel
+func (rp *RowsProcessor) processPipeline(ctx context.Context) error {
+	pipelineCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	// The writers stop waiting for the retries also when another stage has failed
+	rp.setContext(pipelineCtx)
+	defer rp.setContext(ctx)
+
+	depth := rp.Dataset.PipelineDepth
+	if depth <= 0 {
+		depth = PIPELINE_DEPTH
+	}
+	rows := make(chan pipelineRow, PIPELINE_ROWS_BUFFER)
+	rp.batches = make(chan *rowsBatch, depth)
+	defer func() { rp.batches = nil }()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		defer close(rows)
+		if err := rp.readRows(pipelineCtx, rows); err != nil {
+			cancel(err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		defer close(rp.batches)
+		if err := rp.formatRows(pipelineCtx, rows); err != nil {
+			cancel(err)
+		}
+	}()
+	var writeErr error
+	if len(rp.Writers) > 0 {
+		writeErr = rp.writeParallel(pipelineCtx, rp.batches, cancel)
+	} else {
+		writeErr = rp.writeBatches(pipelineCtx, rp.batches)
+	}
+	if writeErr != nil {
+		cancel(writeErr)
+	}
+	wg.Wait()
+	// The errors of the parallel writers are joined, so they are returned even if another stage has failed first
+	if writeErr != nil && !errors.Is(writeErr, ErrCancelled) {
+		return writeErr
+	}
+	return context.Cause(pipelineCtx)
+}
+
+// readRows reads the rows from the data reader and sends a copy of each row with the position
+// of the data reader after it to the given channel until all rows are read.
+func (rp *RowsProcessor) readRows(ctx context.Context, rows chan<- pipelineRow) error {
+	first := true
+	for {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: %w", ErrCancelled, context.Cause(ctx))
+		}
+		next, err := rp.DataReader.Next(ctx)
+		if err != nil {
+			return fmt.Errorf("error reading next row: %w", err)
+		}
+		if !next {
+			return nil
+		}
+		if first {
+			rp.setColumns()
+			first = false
+		}
+		values, err := rp.DataReader.Scan()
+		if err != nil {
+			return fmt.Errorf("error scanning row: %w", err)
+		}
+		row := pipelineRow{values: make([]any, len(values)), state: rp.DataReader.GetState()}
+		copy(row.values, values)
+		select {
+		case rows <- row:
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ErrCancelled, context.Cause(ctx))
+		}
+	}
+}
+
+// formatRows appends the rows from the given channel to the batches, which are sent to the writer
+// when they are full. The last batch is sent when the channel is closed after all rows are read.
+// See: RowsProcessor.appendRow
+func (rp *RowsProcessor) formatRows(ctx context.Context, rows <-chan pipelineRow) error {
+	var before appdb.ReaderState
+	for row := range rows {
+		after := row.state
+		if err := rp.appendRow(ctx, row.values, before, func() appdb.ReaderState { return after }); err != nil {
+			return err
+		}
+		before = row.state
+	}
+	// The channel is also closed when the reader has failed or has been cancelled
+	if ctx.Err() != nil {
+		return fmt.Errorf("%w: %w", ErrCancelled, context.Cause(ctx))
+	}
+	if rp.count == 0 {
+		return nil
+	}
+	batch := rp.takeBatch()
+	batch.last = true
+	select {
+	case rp.batches <- batch:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", ErrCancelled, context.Cause(ctx))
+	}
+}
+
+// writeBatches writes the batches from the given channel to the processors until the channel is closed.
+// The last batch is written without the report, like the last batch of the rows processed one after another.
+// See: RowsProcessor.writeAndReport
+func (rp *RowsProcessor) writeBatches(ctx context.Context, batches <-chan *rowsBatch) error {
+	for {
+		// No batch is written after the pipeline is cancelled, even if it is ready
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: %w", ErrCancelled, context.Cause(ctx))
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ErrCancelled, context.Cause(ctx))
+		case batch, ok := <-batches:
+			if !ok {
+				return nil
+			}
+			var err error
+			if batch.last {
+				err = rp.writeBatch(batch)
+			} else {
+				err = rp.writeAndReport(batch)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeParallel writes the batches from the given channel with all writers in parallel, each writer
+// in its own goroutine takes the next batch when it has written the previous one, so each batch is written
+// by one writer. The Processors write the batches in order when the batch and all earlier batches are written
+// and committed by the writers, and then the position after the batch is reported, so it never passes a batch
+// that is still being written by another writer.
+// If a writer fails, the others complete the batches being written and stop, and the errors of all
+// failed writers are joined. The failed writers are not excluded like with SINK_ERROR_CONTINUE,
+// as the batches they have taken would be lost.
+func (rp *RowsProcessor) writeParallel(ctx context.Context, batches <-chan *rowsBatch, cancel context.CancelCauseFunc) error {
+	cp := &checkpoint{done: make(map[int64]*rowsBatch)}
+	errs := make([]error, len(rp.Writers))
+	var wg sync.WaitGroup
+	for i, processor := range rp.Writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := rp.writeWith(ctx, processor, batches, cp); err != nil {
+				errs[i] = err
+				cancel(err)
+			}
+		}()
+	}
+	wg.Wait()
+	// The writers stopped because another writer has failed are not failed
+	failed := make([]error, 0, len(errs))
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, ErrCancelled) {
+			failed = append(failed, err)
+		}
+	}
+	return errors.Join(failed...)
+}
+
+// writeWith writes the batches from the given channel with the given writer until the channel is closed.
+// The batches written in a transaction are confirmed when the transaction is committed. The last transaction
+// is committed when all batches are written, so its batches are written by the Processors like the others.
+// See: RowsProcessor.confirm
+func (rp *RowsProcessor) writeWith(ctx context.Context, processor RowsProcessorInterface, batches <-chan *rowsBatch, cp *checkpoint) error {
+	pending := make([]*rowsBatch, 0)
+	for {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: %w", ErrCancelled, context.Cause(ctx))
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ErrCancelled, context.Cause(ctx))
+		case batch, ok := <-batches:
+			if !ok {
+				return rp.commitWith(ctx, cp, processor, pending)
+			}
+			start := time.Now()
+			if err := rp.writeTo(processor, batch); err != nil {
+				return fmt.Errorf("error writing buffer to processor: %s: %w", processor.GetProcessedMsg(), err)
+			}
+			batch.latency = time.Since(start)
+			pending = append(pending, batch)
+			if txProcessor, ok := processor.(RowsProcessorTransactionInterface); ok && txProcessor.InTransaction() {
+				continue
+			}
+			if err := rp.confirm(cp, processor, pending); err != nil {
+				return err
+			}
+			pending = make([]*rowsBatch, 0)
+		}
+	}
+}
+
+// commitWith commits the transaction of the given writer with the given batches written after
+// the last commit and confirms them. Nothing is committed if the pipeline has been cancelled.
+func (rp *RowsProcessor) commitWith(ctx context.Context, cp *checkpoint, processor RowsProcessorInterface, batches []*rowsBatch) error {
+	if len(batches) == 0 || ctx.Err() != nil {
+		return nil
+	}
+	if txProcessor, ok := processor.(RowsProcessorTransactionInterface); ok {
+		if err := txProcessor.Commit(); err != nil {
+			return fmt.Errorf("error committing processor: %s: %w", processor.GetProcessedMsg(), err)
+		}
+	}
+	return rp.confirm(cp, processor, batches)
+}
+
+// confirm marks the given batches written by the given writer as done, writes the sequence of the done
+// batches from the first batch that has not been written by the Processors to them in order and fires
+// OnBatchWritten with the position after the last batch of the sequence. The latency of the batches with
+// the current rows per command adjusts the rows with AdaptiveRows.
+func (rp *RowsProcessor) confirm(cp *checkpoint, processor RowsProcessorInterface, batches []*rowsBatch) error {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	for _, batch := range batches {
+		cp.done[batch.seq] = batch
+		if rp.adaptive != nil && batch.full {
+			rp.latency = batch.latency
+			rp.adjustRows()
+		}
+	}
+	var written *rowsBatch
+	for {
+		batch, ok := cp.done[cp.next]
+		if !ok {
+			break
+		}
+		delete(cp.done, cp.next)
+		cp.next++
+		err := rp.forEachProcessor("error writing buffer to processor", func(processor RowsProcessorInterface) error {
+			return rp.writeTo(processor, batch)
+		})
+		if err != nil {
+			return err
+		}
+		// The position after the last batch is saved as completed
+		if !batch.last {
+			written = batch
+		}
+	}
+	if written == nil || len(rp.failed) > 0 || rp.inTransaction() {
+		return nil
+	}
+	rp.OnBatchWritten.Trigger(BatchProgress{State: written.state, Rows: written.rowsCount})
+	rp.WriteLog("info", processor.GetProcessedMsg(), "...:", written.rowsCount)
+	for i, processor := range rp.Processors {
+		if rp.failed[i] == nil {
+			rp.WriteLog("info", processor.GetProcessedMsg(), "...:", written.rowsCount)
+		}
+	}
+	return nil
+}
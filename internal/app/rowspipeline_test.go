@@ -0,0 +1,319 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"context"
+	"copysqldatatool/internal/appdb"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// preparePipelineSqliteDb creates the source SQLite database with 40 rows of the table TBL_NAME.
+func preparePipelineSqliteDb(t *testing.T) *appdb.AppDb {
+	src := prepareSqliteDb(t, "src.db")
+	_, err := src.Exec("WITH RECURSIVE ids(id) AS (SELECT 4 UNION ALL SELECT id + 1 FROM ids WHERE id < 40) " +
+		INSERT_INTO + TBL_NAME + " SELECT id, 'name' || id FROM ids")
+	assert.Nil(t, err)
+	return src
+}
+
+// TestProcessSqlitePipeline verifies that the pipeline writes the same batches as the rows processed
+// one after another and reports the position and the number of rows after each written batch in order,
+// although the rows are read ahead of the writer.
+func TestProcessSqlitePipeline(t *testing.T) {
+	src := preparePipelineSqliteDb(t)
+	for _, statementType := range []string{STATEMENT_TYPE_PREPARED, STATEMENT_TYPE_RAW} {
+		buffers := map[bool][][]string{}
+		for _, pipeline := range []bool{false, true} {
+			processor := &testProcessor{}
+			p := prepareSqliteProcessor(src, processor, 7)
+			p.DataReader.Query = SELECT_FROM + TBL_NAME + " WHERE id > {{id}} ORDER BY id LIMIT 10"
+			p.Dataset.SqlStatementType = statementType
+			p.Dataset.MaxBatchBytes = 200
+			p.Dataset.Pipeline = pipeline
+			progresses := []BatchProgress{}
+			p.OnBatchWritten.Subscribe(func(data any) {
+				progresses = append(progresses, data.(BatchProgress))
+			})
+			err := p.Process(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, int64(40), p.GetRowsCount())
+			assert.Len(t, progresses, len(processor.buffers)-1)
+			written := int64(0)
+			for i, progress := range progresses {
+				written += int64(len(processor.buffers[i]) - 2)
+				assert.Equal(t, written, progress.State.LastId, statementType)
+				assert.Equal(t, written, progress.Rows, statementType)
+			}
+			buffers[pipeline] = processor.buffers
+		}
+		assert.Equal(t, buffers[false], buffers[true], statementType)
+	}
+}
+
+// TestProcessSqlitePipelineDb verifies that the pipeline copies all rows between SQLite databases
+// in transactions and commits them at the end.
+func TestProcessSqlitePipelineDb(t *testing.T) {
+	src := preparePipelineSqliteDb(t)
+	db := prepareSqliteDb(t, "dst.db")
+	_, err := db.Exec("DELETE FROM " + TBL_NAME_2)
+	assert.Nil(t, err)
+	p := prepareSqliteProcessor(src, &DbProcessor{AppDb: db, TableName: TBL_NAME_2, BatchesPerTransaction: 2}, 3)
+	p.DataReader.Query = SELECT_FROM + TBL_NAME + " WHERE id > {{id}} ORDER BY id LIMIT 10"
+	p.Dataset.Pipeline = true
+	err = p.Process(context.Background())
+	assert.Nil(t, err)
+	assert.False(t, db.InTransaction())
+	count, err := db.GetScalar("SELECT COUNT(*) FROM " + TBL_NAME_2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(40), count)
+}
+
+// blockingProcessor is a test processor that blocks the first write until the given channel is closed.
+type blockingProcessor struct {
+	testProcessor
+	unblock chan struct{}
+	timeout bool
+}
+
+// Write waits for the channel to be closed before the first write.
+func (p *blockingProcessor) Write(buffer []string, data []any) error {
+	if len(p.buffers) == 0 {
+		select {
+		case <-p.unblock:
+		case <-time.After(5 * time.Second):
+			p.timeout = true
+		}
+	}
+	return p.testProcessor.Write(buffer, data)
+}
+
+// TestProcessSqlitePipelineReadAhead verifies that the queries of the next chunks are executed
+// while the first batch is being written.
+func TestProcessSqlitePipelineReadAhead(t *testing.T) {
+	src := preparePipelineSqliteDb(t)
+	processor := &blockingProcessor{unblock: make(chan struct{})}
+	p := prepareSqliteProcessor(src, processor, 5)
+	p.DataReader.Query = SELECT_FROM + TBL_NAME + " WHERE id > {{id}} ORDER BY id LIMIT 5"
+	p.Dataset.Pipeline = true
+	queries := atomic.Int64{}
+	p.DataReader.OnQueryChanged.Subscribe(func(data any) {
+		if queries.Add(1) == 3 {
+			close(processor.unblock)
+		}
+	})
+	err := p.Process(context.Background())
+	assert.Nil(t, err)
+	assert.False(t, processor.timeout, "the next chunks were not read while the batch was written")
+	assert.Len(t, processor.buffers, 8)
+}
+
+// TestProcessSqlitePipelineError verifies that the pipeline stops with the error of the failed stage.
+func TestProcessSqlitePipelineError(t *testing.T) {
+	src := preparePipelineSqliteDb(t)
+	processor := &testProcessor{err: errors.New("write error")}
+	p := prepareSqliteProcessor(src, processor, 5)
+	p.Dataset.Pipeline = true
+	err := p.Process(context.Background())
+	assert.ErrorContains(t, err, "write error")
+	assert.NotErrorIs(t, err, ErrCancelled)
+
+	p = prepareSqliteProcessor(src, &testProcessor{}, 5)
+	p.DataReader.Query = "SELECT * FROM unknown_table WHERE id > {{id}} LIMIT 5"
+	p.Dataset.Pipeline = true
+	err = p.Process(context.Background())
+	assert.ErrorContains(t, err, "error reading next row")
+}
+
+// TestProcessSqlitePipelineCancel verifies that the cancelled pipeline completes the batch being written,
+// reports its position, does not write the batches read ahead and returns ErrCancelled.
+func TestProcessSqlitePipelineCancel(t *testing.T) {
+	src := preparePipelineSqliteDb(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	processor := &cancellingProcessor{cancel: cancel}
+	p := prepareSqliteProcessor(src, processor, 1)
+	p.Dataset.Pipeline = true
+	states := []appdb.ReaderState{}
+	p.OnBatchWritten.Subscribe(func(data any) {
+		states = append(states, data.(BatchProgress).State)
+	})
+	err := p.Process(ctx)
+	assert.ErrorIs(t, err, ErrCancelled)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, processor.buffers, 1)
+	if assert.Len(t, states, 1) {
+		assert.Equal(t, int64(1), states[0].LastId, fmt.Sprint(states))
+	}
+}
+
+// parallelProcessor is a test processor of the parallel writers that records the ids of the written rows
+// of prepared statements in the set shared by the writers.
+type parallelProcessor struct {
+	testProcessor
+	mutex   *sync.Mutex
+	written map[int64]int
+	delay   time.Duration
+	// started is done when each writer has received its first batch.
+	started *sync.WaitGroup
+	first   sync.Once
+}
+
+// Write waits until all writers have received a batch, so each writer writes at least one batch,
+// then waits for the delay, which differs between the writers, so the batches are completed out of order,
+// and records the ids of the rows.
+func (p *parallelProcessor) Write(buffer []string, data []any) error {
+	p.first.Do(func() {
+		p.started.Done()
+		p.started.Wait()
+	})
+	time.Sleep(p.delay)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for i := 0; i < len(data); i += 2 {
+		p.written[data[i].(int64)]++
+	}
+	return p.testProcessor.Write(buffer, data)
+}
+
+// orderedProcessor is a test processor that records the ids of the written rows of prepared statements in order.
+type orderedProcessor struct {
+	testProcessor
+	ids []int64
+}
+
+// Write records the ids of the rows.
+func (p *orderedProcessor) Write(buffer []string, data []any) error {
+	for i := 0; i < len(data); i += 2 {
+		p.ids = append(p.ids, data[i].(int64))
+	}
+	return p.testProcessor.Write(buffer, data)
+}
+
+// TestProcessSqliteParallelWriters verifies that each batch is written once by one of the parallel writers,
+// that the processors write all batches in order after the writers and that the reported position never passes
+// a row that has not been written yet.
+func TestProcessSqliteParallelWriters(t *testing.T) {
+	src := preparePipelineSqliteDb(t)
+	mutex := &sync.Mutex{}
+	written := map[int64]int{}
+	started := &sync.WaitGroup{}
+	started.Add(3)
+	processors := []*parallelProcessor{}
+	for i := range 3 {
+		processors = append(processors, &parallelProcessor{mutex: mutex, written: written, delay: time.Duration(i*3) * time.Millisecond, started: started})
+	}
+	sink := &orderedProcessor{}
+	p := prepareSqliteProcessor(src, sink, 3)
+	p.Writers = []RowsProcessorInterface{processors[0], processors[1], processors[2]}
+	p.DataReader.Query = SELECT_FROM + TBL_NAME + " WHERE id > {{id}} ORDER BY id LIMIT 10"
+	lastId := int64(0)
+	p.OnBatchWritten.Subscribe(func(data any) {
+		state := data.(BatchProgress).State
+		assert.Equal(t, state.LastId, data.(BatchProgress).Rows)
+		assert.Greater(t, state.LastId, lastId)
+		lastId = state.LastId
+		mutex.Lock()
+		defer mutex.Unlock()
+		for id := int64(1); id <= state.LastId; id++ {
+			assert.Equal(t, 1, written[id], "row %d is not written before position %d", id, state.LastId)
+		}
+		// The last batch may be written with the batches before it, it is not reported
+		assert.GreaterOrEqual(t, len(sink.ids), int(state.LastId))
+		assert.Equal(t, state.LastId, sink.ids[state.LastId-1])
+	})
+	err := p.Process(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, written, 40)
+	for id, count := range written {
+		assert.Equal(t, 1, count, id)
+	}
+	for _, processor := range processors {
+		assert.NotEmpty(t, processor.buffers)
+	}
+	assert.Equal(t, int64(39), lastId)
+	expected := make([]int64, 0, 40)
+	for id := range int64(40) {
+		expected = append(expected, id+1)
+	}
+	assert.Equal(t, expected, sink.ids)
+}
+
+// TestProcessSqliteParallelWritersTransaction verifies that the last transactions of the writers are committed
+// when all batches are written, so the processors write the batches of the transactions too.
+func TestProcessSqliteParallelWritersTransaction(t *testing.T) {
+	src := preparePipelineSqliteDb(t)
+	sink := &orderedProcessor{}
+	first := &transactionProcessor{}
+	second := &transactionProcessor{}
+	p := prepareSqliteProcessor(src, sink, 3)
+	p.Writers = []RowsProcessorInterface{first, second}
+	p.DataReader.Query = SELECT_FROM + TBL_NAME + " WHERE id > {{id}} ORDER BY id LIMIT 10"
+	err := p.Process(context.Background())
+	assert.Nil(t, err)
+	assert.False(t, first.InTransaction())
+	assert.False(t, second.InTransaction())
+	assert.Len(t, sink.ids, 40)
+	assert.Equal(t, len(first.buffers)+len(second.buffers), len(sink.buffers))
+}
+
+// TestRowsProcessorConfirm verifies that the position is reported only for the sequence of the written batches
+// from the first batch that has not been reported, and that the last batch is not reported.
+func TestRowsProcessorConfirm(t *testing.T) {
+	p := RowsProcessor{}
+	states := []int64{}
+	p.OnBatchWritten.Subscribe(func(data any) {
+		states = append(states, data.(BatchProgress).State.LastId)
+	})
+	cp := &checkpoint{done: make(map[int64]*rowsBatch)}
+	batch := func(seq int64) *rowsBatch {
+		return &rowsBatch{seq: seq, state: appdb.ReaderState{LastId: (seq + 1) * 10}}
+	}
+	processor := &testProcessor{}
+	assert.Nil(t, p.confirm(cp, processor, []*rowsBatch{batch(1), batch(2)}))
+	assert.Empty(t, states)
+	assert.Nil(t, p.confirm(cp, processor, []*rowsBatch{batch(0)}))
+	assert.Equal(t, []int64{30}, states)
+	assert.Nil(t, p.confirm(cp, processor, []*rowsBatch{{seq: 4, last: true}}))
+	assert.Nil(t, p.confirm(cp, processor, []*rowsBatch{batch(3)}))
+	assert.Equal(t, []int64{30, 40}, states)
+	assert.Empty(t, cp.done)
+}
+
+// failingParallelProcessor is a test processor of the parallel writers that fails after all writers
+// have started writing a batch.
+type failingParallelProcessor struct {
+	testProcessor
+	started *sync.WaitGroup
+}
+
+// Write waits until all writers have started writing and fails.
+func (p *failingParallelProcessor) Write(buffer []string, data []any) error {
+	p.started.Done()
+	p.started.Wait()
+	return fmt.Errorf("write error %p", p)
+}
+
+// TestProcessSqliteParallelWritersErrors verifies that the errors of all failed writers are returned.
+func TestProcessSqliteParallelWritersErrors(t *testing.T) {
+	src := preparePipelineSqliteDb(t)
+	started := &sync.WaitGroup{}
+	started.Add(2)
+	first := &failingParallelProcessor{started: started}
+	second := &failingParallelProcessor{started: started}
+	p := prepareSqliteProcessor(src, &testProcessor{}, 3)
+	p.Writers = []RowsProcessorInterface{first, second}
+	p.DataReader.Query = SELECT_FROM + TBL_NAME + " WHERE id > {{id}} ORDER BY id LIMIT 10"
+	err := p.Process(context.Background())
+	assert.ErrorContains(t, err, fmt.Sprintf("write error %p", first))
+	assert.ErrorContains(t, err, fmt.Sprintf("write error %p", second))
+	assert.NotErrorIs(t, err, ErrCancelled)
+}
@@ -4,23 +4,52 @@
 package app
 
 import (
+	"context"
 	"copysqldatatool/internal/appbuffer"
 	"copysqldatatool/internal/appdb"
+	"copysqldatatool/internal/appevent"
 	"copysqldatatool/internal/applog"
+	"errors"
 	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
 )
 
+// ErrCancelled is returned by RowsProcessor.Process when the processing is cancelled by the context.
+var ErrCancelled = errors.New("processing cancelled")
+
+// BatchProgress is the data of the event RowsProcessor.OnBatchWritten.
+type BatchProgress struct {
+	// Position of the data reader after the last written row.
+	State appdb.ReaderState
+	// Number of rows processed up to the end of the written batch. In the pipeline the rows are read
+	// ahead of the writer, so it is less than the number of rows processed so far.
+	Rows int64
+}
+
 // RowsProcessor manages the processing of database rows for data transfer or manipulation.
 // It handles reading data, formatting, buffering, and writing rows with configurable processing.
+// Every batch of rows is written to all processors, so the source data is read only once.
 type RowsProcessor struct {
-	// Interface for processing rows.
-	Processor RowsProcessorInterface
+	// Processors to write rows to.
+	Processors []RowsProcessorInterface
+	// Writers to separate connections of the same database, which write the batches in parallel.
+	// Each batch is written by one of the writers and then by all Processors in order.
+	// The rows are processed in the pipeline. See: RowsProcessor.writeParallel
+	Writers []RowsProcessorInterface
 	// Data reader for retrieving rows from the source database.
 	DataReader *appdb.DataReader
 	// Log for recording processing details.
 	Log *applog.AppLog
 	// Dataset configuration for SQL insertion operations.
 	Dataset Dataset
+	// Event fired after a full batch of rows is written to all processors.
+	// The event is not fired after any processor has failed.
+	// The event data is the BatchProgress with the position of the data reader after the last written row.
+	OnBatchWritten appevent.AppEvent
+	// Writer of the rows rejected by the processors if the OnRowError of the dataset is ROW_ERROR_DEADLETTER.
+	Rejects RejectWriterInterface
 	// Buffer for storing formatted rows.
 	buffer *appbuffer.AppBuffer
 	// Data to be written to the processor.
@@ -29,16 +58,50 @@ type RowsProcessor struct {
 	formatter *appdb.Formatter
 	// Columns to be used for formatting.
 	columns []string
+	// Columns of the source rows for the processors that write the row values.
+	rowColumns []appdb.Column
+	// Rows to be written to the processors that write the row values.
+	rows [][]any
+	// True if any processor writes SQL statements, so the INSERT statements have to be built.
+	buildStatements bool
+	// True if any processor writes the row values or the rejected batches are split,
+	// so the rows have to be collected.
+	collectRows bool
 	// Count of rows processed in one insert command.
 	count int64
+	// Rows per command of the current batch. It is RowsPerCommand of the dataset unless AdaptiveRows is set.
+	// It is atomic, as the writer of the pipeline adjusts it while the rows are formatted.
+	rowsPerCommand atomic.Int64
+	// Adjuster of the rows per command if AdaptiveRows of the dataset is set, otherwise nil.
+	adaptive *AdaptiveRows
+	// Time of writing the last batch to all processors.
+	latency time.Duration
+	// Size in bytes of the rows of the insert command with the values of the prepared statement.
+	batchBytes int64
+	// Size in bytes of the INSERT command preceding the rows.
+	commandBytes int64
 	// All processed rows counter.
 	rowsCount int64
+	// Counter of the rows rejected by the processors. It is atomic, as the parallel writers reject rows concurrently.
+	rejected atomic.Int64
+	// Sequence number of the next batch.
+	batchSeq int64
+	// Errors of the failed processors by processor index.
+	failed map[int]error
+	// Channel of the batches to the writer of the pipeline, nil if the rows are not processed in the pipeline.
+	batches chan *rowsBatch
 }
 
 // Process opens the data reader, reads rows, formats them according to the set InsertCommand and SqlStatement,
 // and writes the formatted rows to the processor. It also handles closing the data reader and processing any remaining
-// rows.
-func (rp *RowsProcessor) Process() error {
+// rows. Processors that write rows in transactions are committed at the end and rolled back on error.
+// Processors that have to finish writing, for example to write the file footer, are finished at the end.
+// If Pipeline of the dataset or Writers are set, the rows are read, formatted and written in parallel.
+// When the given context is done, the reading of the source rows is cancelled, the batch being written
+// is completed, and the rows read after the last written batch and the uncommitted rows are rolled back.
+// The returned error wraps ErrCancelled in this case.
+// See: RowsProcessor.processPipeline
+func (rp *RowsProcessor) Process(ctx context.Context) error {
 	rp.reset()
 	err := rp.DataReader.Open()
 	if err != nil {
@@ -46,8 +109,52 @@ func (rp *RowsProcessor) Process() error {
 	}
 	defer rp.DataReader.Close()
 
+	rp.setContext(ctx)
+	if rp.Dataset.Pipeline || len(rp.Writers) > 0 {
+		err = rp.processPipeline(ctx)
+	} else {
+		err = rp.processRows(ctx)
+	}
+	if err != nil {
+		rp.rollback()
+		if ctx.Err() != nil && !errors.Is(err, ErrCancelled) {
+			if cause := context.Cause(ctx); !errors.Is(err, cause) {
+				return fmt.Errorf("%w: %w: %w", ErrCancelled, cause, err)
+			}
+			return fmt.Errorf("%w: %w", ErrCancelled, err)
+		}
+		return err
+	}
+
+	if err := rp.finish(); err != nil {
+		rp.rollback()
+		return err
+	}
+
+	if err := rp.commit(); err != nil {
+		rp.rollback()
+		return err
+	}
+
+	for i, processor := range rp.Processors {
+		if rp.failed[i] == nil {
+			rp.WriteLog("ok", processor.GetProcessedMsg(), ":", rp.rowsCount)
+		}
+	}
+	// The writers have written the rows together
+	if len(rp.Writers) > 0 {
+		rp.WriteLog("ok", rp.Writers[0].GetProcessedMsg(), ":", rp.rowsCount)
+	}
+	if rp.adaptive != nil {
+		rp.WriteLog("info", "Adaptive rows per command:", rp.rowsPerCommand.Load())
+	}
+	return rp.failedError()
+}
+
+// processRows reads, formats and writes the rows one after another until all rows are written.
+func (rp *RowsProcessor) processRows(ctx context.Context) error {
 	for {
-		next, err := rp.processRow()
+		next, err := rp.processRow(ctx)
 		if err != nil {
 			return err
 		}
@@ -55,37 +162,71 @@ func (rp *RowsProcessor) Process() error {
 			break
 		}
 	}
-
-	if rp.buffer.Len() > 0 {
-		rp.buffer.AppendStr(";")
-		if err := rp.Processor.Write(rp.buffer.GetBuffer(), rp.data); err != nil {
-			return fmt.Errorf("error writing buffer to file: %w", err)
-		}
-		rp.buffer.Clear()
-		rp.data = make([]any, 0)
+	if rp.count > 0 {
+		return rp.write()
 	}
-
-	rp.WriteLog("ok", rp.Processor.GetProcessedMsg(), ":", rp.rowsCount)
 	return nil
 }
 
 // reset resets the RowsProcessor to its initial state. It resets the count and rowsCount, clears the columns,
-// resets the formatter, buffer, and data.
+// resets the formatter, buffer, data and rows, and checks which kinds of output the processors and writers need.
 func (rp *RowsProcessor) reset() {
 	rp.count = 0
+	rp.rowsPerCommand.Store(rp.Dataset.RowsPerCommand)
+	rp.adaptive = nil
+	if rp.Dataset.AdaptiveRows {
+		rp.adaptive = &AdaptiveRows{
+			MinRows:       rp.Dataset.MinRowsPerCommand,
+			MaxRows:       rp.Dataset.MaxRowsPerCommand,
+			TargetLatency: rp.Dataset.TargetLatency,
+		}
+		rp.rowsPerCommand.Store(rp.adaptive.Start(rp.Dataset.RowsPerCommand))
+		rp.WriteLog("info", "Adaptive rows per command:", rp.rowsPerCommand.Load(), "Min:", rp.adaptive.MinRows,
+			"Max:", rp.adaptive.MaxRows, "Target latency:", rp.adaptive.TargetLatency)
+	}
+	rp.batchBytes = 0
+	rp.commandBytes = 0
 	rp.rowsCount = 0
+	rp.rejected.Store(0)
+	rp.batchSeq = 0
 	rp.columns = make([]string, 0)
-	rp.formatter = &appdb.Formatter{}
+	rp.rowColumns = make([]appdb.Column, 0)
+	dialectFactory := appdb.DialectFactory{}
+	rp.formatter = &appdb.Formatter{Dialect: dialectFactory.CreateDialect(rp.Dataset.Driver)}
 	rp.buffer = &appbuffer.AppBuffer{}
 	rp.data = make([]any, 0)
+	rp.rows = make([][]any, 0)
+	rp.failed = make(map[int]error)
+	rp.buildStatements = false
+	rp.collectRows = false
+	for _, processor := range slices.Concat(rp.Processors, rp.Writers) {
+		if _, ok := processor.(RowsWriterInterface); ok {
+			rp.collectRows = true
+		} else {
+			rp.buildStatements = true
+		}
+	}
+	if rp.buildStatements && rp.Dataset.OnRowError == ROW_ERROR_DEADLETTER {
+		rp.collectRows = true
+	}
 }
 
-// processRow reads the next row from the data reader, formats it according to the set SqlStatement,
-// appends it to the buffer, and writes the buffer to the processor if the buffer is full.
-// It also handles resetting the buffer and data if the buffer is full.
+// processRow reads the next row from the data reader and appends it to the batch.
 // Returns true if there is more data to be processed, false otherwise.
-func (rp *RowsProcessor) processRow() (bool, error) {
-	next, err := rp.DataReader.Next()
+// It returns ErrCancelled if the given context is done before the next row is read.
+// See: RowsProcessor.appendRow
+func (rp *RowsProcessor) processRow(ctx context.Context) (bool, error) {
+	if ctx.Err() != nil {
+		return false, fmt.Errorf("%w: %w", ErrCancelled, context.Cause(ctx))
+	}
+
+	// The position before the row is reported if the batch is written without the row
+	var state appdb.ReaderState
+	if rp.Dataset.MaxBatchBytes > 0 && rp.count > 0 {
+		state = rp.DataReader.GetState()
+	}
+
+	next, err := rp.DataReader.Next(ctx)
 	if err != nil {
 		return false, fmt.Errorf("error reading next row: %w", err)
 	}
@@ -95,7 +236,7 @@ func (rp *RowsProcessor) processRow() (bool, error) {
 	}
 
 	if rp.rowsCount == 0 {
-		rp.columns = rp.DataReader.WrappedColumns()
+		rp.setColumns()
 	}
 
 	values, err := rp.DataReader.Scan()
@@ -103,39 +244,412 @@ func (rp *RowsProcessor) processRow() (bool, error) {
 		return false, fmt.Errorf("error scanning row: %w", err)
 	}
 
-	insertStatement := rp.formatter.GetInsertStatement(rp.Dataset.SqlStatementType, values)
-	rp.appendRowToBuffer(insertStatement)
-	if rp.Dataset.SqlStatementType == STATEMENT_TYPE_PREPARED {
-		rp.data = append(rp.data, values...)
+	return true, rp.appendRow(ctx, values, state, rp.DataReader.GetState)
+}
+
+// setColumns sets the columns of the rows from the data reader after the first row is read.
+func (rp *RowsProcessor) setColumns() {
+	rp.rowColumns = rp.DataReader.ColumnTypes()
+	rp.columns = rp.formatter.QuoteIdentifiers(rp.DataReader.Columns())
+	rp.commandBytes = int64(len(rp.formatter.GetInsertCommand(rp.Dataset.InsertCommand, rp.Dataset.TableName, rp.columns)))
+}
+
+// appendRow formats the given row values according to the set SqlStatement, appends them to the buffer,
+// and flushes the batch if it is full. The batch is full when it has RowsPerCommand rows (or the number
+// of rows adjusted by AdaptiveRows), when the next row would exceed the max number of placeholders
+// of the destination, or before the row that would exceed MaxBatchBytes. The batch flushed before the row
+// is reported with the given position before the row, the batch flushed after the row with the position
+// returned by the given function.
+// For the processors that write the row values, a copy of the row is collected instead,
+// as the data reader reuses the scanned values.
+func (rp *RowsProcessor) appendRow(ctx context.Context, values []any, before appdb.ReaderState, after func() appdb.ReaderState) error {
+	if rp.buildStatements {
+		insertStatement := rp.formatter.GetInsertStatement(rp.Dataset.SqlStatementType, values, len(rp.data)+1)
+		rowBytes := rp.getRowBytes(insertStatement, values)
+		if rp.count > 0 && rp.Dataset.MaxBatchBytes > 0 && rp.commandBytes+rp.batchBytes+rowBytes > rp.Dataset.MaxBatchBytes {
+			if err := rp.flush(ctx, before, false); err != nil {
+				return err
+			}
+			insertStatement = rp.formatter.GetInsertStatement(rp.Dataset.SqlStatementType, values, 1)
+		}
+		rp.appendRowToBuffer(insertStatement)
+		rp.batchBytes += rowBytes
+		if rp.Dataset.SqlStatementType == STATEMENT_TYPE_PREPARED {
+			rp.data = append(rp.data, values...)
+		}
+	}
+	if rp.collectRows {
+		row := make([]any, len(values))
+		copy(row, values)
+		rp.rows = append(rp.rows, row)
 	}
 	rp.count++
 	rp.rowsCount++
 
-	if rp.count == rp.Dataset.RowsPerCommand {
+	// The writer of the pipeline may reduce the rows per command below the rows of the current batch
+	rowsPerCommand := rp.rowsPerCommand.Load()
+	if rp.count >= rowsPerCommand || rp.isPlaceholdersFull(len(values)) {
+		return rp.flush(ctx, after(), rp.count == rowsPerCommand)
+	}
+	return nil
+}
+
+// adjustRows changes the rows per command according to the write latency of the last batch
+// and logs the change.
+func (rp *RowsProcessor) adjustRows() {
+	rows, changed := rp.adaptive.Adjust(rp.latency)
+	if changed {
+		rp.WriteLog("info", "Rows per command changed:", rp.rowsPerCommand.Load(), "->", rows, "Latency:", rp.latency.Round(time.Millisecond))
+		rp.rowsPerCommand.Store(rows)
+	}
+}
+
+// flush takes the batch with the given position of the data reader after its last row and writes it,
+// or passes it to the writer of the pipeline. If full is true, the batch has the current rows per command.
+// See: RowsProcessor.writeAndReport
+func (rp *RowsProcessor) flush(ctx context.Context, state appdb.ReaderState, full bool) error {
+	batch := rp.takeBatch()
+	batch.state = state
+	batch.full = full
+	if rp.batches == nil {
+		return rp.writeAndReport(batch)
+	}
+	select {
+	case rp.batches <- batch:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", ErrCancelled, context.Cause(ctx))
+	}
+}
+
+// writeAndReport writes the given batch to the processors and fires OnBatchWritten with the position
+// of the data reader after the last row of the batch. It logs the number of processed rows.
+// The latency of the batch with the current rows per command adjusts the rows with AdaptiveRows.
+func (rp *RowsProcessor) writeAndReport(batch *rowsBatch) error {
+	if err := rp.writeBatch(batch); err != nil {
+		return err
+	}
+	if len(rp.failed) == 0 && !rp.inTransaction() {
+		rp.OnBatchWritten.Trigger(BatchProgress{State: batch.state, Rows: batch.rowsCount})
+	}
+	for i, processor := range rp.Processors {
+		if rp.failed[i] == nil {
+			rp.WriteLog("info", processor.GetProcessedMsg(), "...:", batch.rowsCount)
+		}
+	}
+	// Only the batches with the current rows per command show how the latency depends on it
+	if rp.adaptive != nil && batch.full && len(rp.failed) == 0 {
+		rp.adjustRows()
+	}
+	return nil
+}
+
+// isPlaceholdersFull returns true if the prepared statement in the buffer cannot take another row
+// with the given number of values without exceeding the max number of placeholders of the destination.
+func (rp *RowsProcessor) isPlaceholdersFull(rowValues int) bool {
+	if !rp.buildStatements || rp.Dataset.SqlStatementType != STATEMENT_TYPE_PREPARED {
+		return false
+	}
+	maxPlaceholders := rp.formatter.Dialect.MaxPlaceholders()
+	return maxPlaceholders > 0 && len(rp.data)+rowValues > maxPlaceholders
+}
+
+// getRowBytes returns the size in bytes that the row with the given formatted statement adds to the batch.
+// The values of the prepared statements are sent with the statement, so their size is added.
+func (rp *RowsProcessor) getRowBytes(insertStatement string, values []any) int64 {
+	// The parentheses and the comma separating the rows
+	size := int64(len(insertStatement) + 4)
+	if rp.Dataset.SqlStatementType != STATEMENT_TYPE_PREPARED {
+		return size
+	}
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case []byte:
+			size += int64(len(v))
+		case string:
+			size += int64(len(v))
+		default:
+			size += 8
+		}
+	}
+	return size
+}
+
+// write writes the current batch to all processors.
+func (rp *RowsProcessor) write() error {
+	return rp.writeBatch(rp.takeBatch())
+}
+
+// takeBatch returns the current batch and starts a new one.
+func (rp *RowsProcessor) takeBatch() *rowsBatch {
+	if rp.buildStatements {
 		rp.buffer.AppendStr(";")
-		if err := rp.Processor.Write(rp.buffer.GetBuffer(), rp.data); err != nil {
-			return false, fmt.Errorf("error writing buffer to file: %w", err)
+	}
+	batch := &rowsBatch{
+		buffer:    rp.buffer,
+		data:      rp.data,
+		rows:      rp.rows,
+		count:     rp.count,
+		rowsCount: rp.rowsCount,
+		seq:       rp.batchSeq,
+	}
+	rp.batchSeq++
+	rp.buffer = &appbuffer.AppBuffer{}
+	rp.data = make([]any, 0)
+	rp.rows = make([][]any, 0)
+	rp.count = 0
+	rp.batchBytes = 0
+	return batch
+}
+
+// writeBatch writes the buffer and data of the given batch to all processors that have not failed.
+// The processors that write the row values get the collected rows instead.
+// The processors that need to know the batch boundaries are notified after the batch is written.
+// The time of writing the batch to all processors is kept for AdaptiveRows.
+// See: RowsProcessor.forEachProcessor
+func (rp *RowsProcessor) writeBatch(batch *rowsBatch) error {
+	start := time.Now()
+	err := rp.forEachProcessor("error writing buffer to processor", func(processor RowsProcessorInterface) error {
+		return rp.writeTo(processor, batch)
+	})
+	rp.latency = time.Since(start)
+	return err
+}
+
+// writeTo writes the given batch to the given processor and notifies it about the end of the batch if it needs it.
+// The rejected rows of the batch are split to find the bad rows if the OnRowError of the dataset is ROW_ERROR_DEADLETTER.
+func (rp *RowsProcessor) writeTo(processor RowsProcessorInterface, batch *rowsBatch) error {
+	var err error
+	if rowsWriter, ok := processor.(RowsWriterInterface); ok {
+		err = rowsWriter.WriteRows(rp.rowColumns, batch.rows)
+	} else {
+		err = processor.Write(batch.buffer.GetBuffer(), batch.data)
+		if err != nil && rp.canSplit(err) && !rp.isAborted(processor) {
+			err = rp.writeSplit(processor, batch.rows, err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if batchProcessor, ok := processor.(RowsProcessorBatchInterface); ok {
+		return batchProcessor.EndBatch(batch.count)
+	}
+	return nil
+}
+
+// canSplit returns true if the batch failed with the given error can be split to find the rejected rows.
+// Only the errors caused by the data are split, the transient errors, timeouts and cancellations
+// would fail all parts of the batch.
+func (rp *RowsProcessor) canSplit(err error) bool {
+	return rp.Dataset.OnRowError == ROW_ERROR_DEADLETTER && !appdb.IsTransientError(rp.formatter.Dialect, err) &&
+		!errors.Is(err, appdb.ErrTimeout) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// isAborted returns true if the failed batch has aborted the transaction of the given processor.
+// PostgreSQL rejects all statements of a transaction after the first error until it is rolled back,
+// so the parts of the batch cannot be written in the transaction.
+func (rp *RowsProcessor) isAborted(processor RowsProcessorInterface) bool {
+	txProcessor, ok := processor.(RowsProcessorTransactionInterface)
+	return ok && txProcessor.InTransaction() && rp.Dataset.Driver == appdb.DRIVER_POSTGRES
+}
+
+// writeSplit writes the given rows that have failed with the given error to the given processor split in halves.
+// A half that fails is split again until the rejected rows are found, which are written to the Rejects writer
+// with the error of the processor. The other rows are written to the processor.
+// It returns an error if the rows cannot be written or the number of rejected rows exceeds MaxRejects.
+func (rp *RowsProcessor) writeSplit(processor RowsProcessorInterface, rows [][]any, err error) error {
+	if len(rows) == 1 {
+		return rp.reject(rows[0], err)
+	}
+	half := len(rows) / 2
+	for _, part := range [][][]any{rows[:half], rows[half:]} {
+		buffer, data := rp.buildStatement(part)
+		err := processor.Write(buffer, data)
+		if err == nil {
+			continue
+		}
+		if !rp.canSplit(err) {
+			return err
+		}
+		if err := rp.writeSplit(processor, part, err); err != nil {
+			return err
 		}
-		rp.buffer.Clear()
-		rp.data = make([]any, 0)
-		rp.count = 0
-		rp.WriteLog("info", rp.Processor.GetProcessedMsg(), "...:", rp.rowsCount)
 	}
-	return true, nil
+	return nil
+}
+
+// reject writes the given row rejected with the given error to the Rejects writer.
+// It returns an error if the writer fails, for example if the max number of rejected rows is exceeded.
+func (rp *RowsProcessor) reject(row []any, reason error) error {
+	if rp.Rejects == nil {
+		return fmt.Errorf("writer of rejected rows is not set: %w", reason)
+	}
+	if err := rp.Rejects.WriteReject(rp.rowColumns, row, reason); err != nil {
+		return err
+	}
+	rp.rejected.Add(1)
+	rp.WriteLog("warn", "Row rejected:", reason)
+	return nil
+}
+
+// buildStatement returns the INSERT statement of the given rows and its data for the prepared statements.
+func (rp *RowsProcessor) buildStatement(rows [][]any) ([]string, []any) {
+	buffer := &appbuffer.AppBuffer{}
+	data := make([]any, 0)
+	for i, values := range rows {
+		insertStatement := rp.formatter.GetInsertStatement(rp.Dataset.SqlStatementType, values, len(data)+1)
+		rp.appendStatement(buffer, i == 0, insertStatement)
+		if rp.Dataset.SqlStatementType == STATEMENT_TYPE_PREPARED {
+			data = append(data, values...)
+		}
+	}
+	buffer.AppendStr(";")
+	return buffer.GetBuffer(), data
+}
+
+// finish completes the output of all processors that have not failed and have to finish writing.
+// See: RowsProcessor.forEachProcessor
+func (rp *RowsProcessor) finish() error {
+	return rp.forEachProcessor("error finishing processor", func(processor RowsProcessorInterface) error {
+		if finishProcessor, ok := processor.(RowsProcessorFinishInterface); ok {
+			return finishProcessor.Finish()
+		}
+		return nil
+	})
+}
+
+// commit commits the written rows of all transactional processors that have not failed.
+// See: RowsProcessor.forEachProcessor
+func (rp *RowsProcessor) commit() error {
+	return rp.forEachProcessor("error committing processor", func(processor RowsProcessorInterface) error {
+		if txProcessor, ok := processor.(RowsProcessorTransactionInterface); ok {
+			return txProcessor.Commit()
+		}
+		return nil
+	})
+}
+
+// setContext sets the given context of the processing to the processors and writers that wait
+// between the attempts of writing a batch.
+func (rp *RowsProcessor) setContext(ctx context.Context) {
+	for _, processor := range slices.Concat(rp.Processors, rp.Writers) {
+		if ctxProcessor, ok := processor.(RowsProcessorContextInterface); ok {
+			ctxProcessor.SetContext(ctx)
+		}
+	}
+}
+
+// rollback rolls back the uncommitted rows of all transactional processors and writers.
+// Rollback errors are logged and ignored, as the processing has already failed.
+func (rp *RowsProcessor) rollback() {
+	for _, processor := range slices.Concat(rp.Processors, rp.Writers) {
+		rp.rollbackProcessor(processor)
+	}
+}
+
+// rollbackProcessor rolls back the uncommitted rows of the given processor if it is transactional.
+func (rp *RowsProcessor) rollbackProcessor(processor RowsProcessorInterface) {
+	txProcessor, ok := processor.(RowsProcessorTransactionInterface)
+	if !ok {
+		return
+	}
+	if err := txProcessor.Rollback(); err != nil {
+		rp.WriteLog("warn", "Error rolling back processor:", processor.GetProcessedMsg(), err)
+	}
+}
+
+// inTransaction returns true if any processor that has not failed has uncommitted rows.
+func (rp *RowsProcessor) inTransaction() bool {
+	for i, processor := range rp.Processors {
+		if rp.failed[i] != nil {
+			continue
+		}
+		if txProcessor, ok := processor.(RowsProcessorTransactionInterface); ok && txProcessor.InTransaction() {
+			return true
+		}
+	}
+	return false
+}
+
+// forEachProcessor calls the given action for all processors that have not failed.
+// If a processor fails and the OnSinkError of the dataset is SINK_ERROR_CONTINUE, the processor is
+// rolled back, excluded from further writes and the processing continues with the other processors.
+// Otherwise, or if all processors have failed, it returns an error.
+func (rp *RowsProcessor) forEachProcessor(msg string, action func(processor RowsProcessorInterface) error) error {
+	for i, processor := range rp.Processors {
+		if rp.failed[i] != nil {
+			continue
+		}
+		err := action(processor)
+		if err == nil {
+			continue
+		}
+		err = fmt.Errorf("%s: %s: %w", msg, processor.GetProcessedMsg(), err)
+		if rp.Dataset.OnSinkError != SINK_ERROR_CONTINUE {
+			return err
+		}
+		rp.failed[i] = err
+		rp.rollbackProcessor(processor)
+		rp.WriteLog("error", err, "Continue with other processors")
+	}
+	if len(rp.failed) > 0 && len(rp.failed) == len(rp.Processors) {
+		return rp.failedError()
+	}
+	return nil
+}
+
+// failedError returns an error that joins the errors of all failed processors,
+// or nil if no processor has failed.
+func (rp *RowsProcessor) failedError() error {
+	errs := make([]error, 0, len(rp.failed))
+	for i := range rp.Processors {
+		if rp.failed[i] != nil {
+			errs = append(errs, rp.failed[i])
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// IsFailed returns true if the given processor has failed during the last call of Process.
+func (rp *RowsProcessor) IsFailed(processor RowsProcessorInterface) bool {
+	for i, p := range rp.Processors {
+		if p == processor {
+			return rp.failed[i] != nil
+		}
+	}
+	return false
 }
 
 // appendRowToBuffer appends a row to the buffer in the correct format for the current SQL statement.
 // If the buffer is empty, it adds the INSERT command and the first row in parentheses.
 // If the buffer is not empty, it simply appends the next row in parentheses, separated by a comma.
 func (rp *RowsProcessor) appendRowToBuffer(insertStatement string) {
-	if rp.count == 0 {
-		rp.buffer.AppendStr(rp.formatter.GetInsertCommand(rp.Dataset.InsertCommand, rp.Dataset.TableName, rp.columns))
-		rp.buffer.AppendStr(fmt.Sprintf("(%s)", insertStatement))
+	rp.appendStatement(rp.buffer, rp.count == 0, insertStatement)
+}
+
+// appendStatement appends a row to the given buffer, the first row of the statement is preceded by the INSERT command.
+func (rp *RowsProcessor) appendStatement(buffer *appbuffer.AppBuffer, first bool, insertStatement string) {
+	if first {
+		buffer.AppendStr(rp.formatter.GetInsertCommand(rp.Dataset.InsertCommand, rp.Dataset.TableName, rp.columns))
+		buffer.AppendStr(fmt.Sprintf("(%s)", insertStatement))
 	} else {
-		rp.buffer.AppendStr(fmt.Sprintf(", (%s)", insertStatement))
+		buffer.AppendStr(fmt.Sprintf(", (%s)", insertStatement))
 	}
 }
 
+// GetRowsCount returns the number of rows processed by the last call of Process.
+// It is read after Process has returned, the rows written during processing are reported by OnBatchWritten.
+func (rp *RowsProcessor) GetRowsCount() int64 {
+	return rp.rowsCount
+}
+
+// GetRejectedCount returns the number of rows rejected by the processors during the last call of Process.
+// The rejected rows are included in the number of processed rows.
+func (rp *RowsProcessor) GetRejectedCount() int64 {
+	return rp.rejected.Load()
+}
+
 // WriteLog writes a log message to the RowsProcessor's log if it is not nil.
 // It takes a message type and any number of arguments, and writes the message to the log.
 // It returns the RowsProcessor itself, allowing for method chaining.
@@ -3,8 +3,11 @@
 // Copyright (c) 2025 Aleksei Grigorev
 package app
 
-// RowsProcessorInterface defines the contract for processing and writing rows of data
-// with the ability to retrieve a processed message after completion.
+import (
+	"context"
+	"copysqldatatool/internal/appdb"
+)
+
 // RowsProcessorInterface defines the contract for processing and writing rows of data
 // with the ability to retrieve a processed message after completion.
 type RowsProcessorInterface interface {
@@ -16,3 +19,61 @@ type RowsProcessorInterface interface {
 	// It returns the processed message as a string.
 	GetProcessedMsg() string
 }
+
+// RowsProcessorTransactionInterface is implemented by processors that write rows in transactions.
+// RowsProcessor saves the progress only when no processor has uncommitted rows,
+// commits the processors at the end of processing and rolls them back on error.
+type RowsProcessorTransactionInterface interface {
+	// InTransaction returns true if there are written rows that are not committed yet.
+	InTransaction() bool
+
+	// Commit commits the written rows.
+	// It returns an error if the commit fails.
+	Commit() error
+
+	// Rollback discards the written rows that are not committed yet.
+	// It returns an error if the rollback fails.
+	Rollback() error
+}
+
+// RowsProcessorFinishInterface is implemented by processors that have to finish writing after the last rows,
+// for example to write the footer of the file. RowsProcessor calls Finish at the end of successful processing.
+type RowsProcessorFinishInterface interface {
+	// Finish writes the buffered data and completes the output.
+	// It returns an error if the write operation fails.
+	Finish() error
+}
+
+// RowsProcessorBatchInterface is implemented by processors that need to know the batch boundaries,
+// for example to split the output into several files. RowsProcessor calls EndBatch after each written batch.
+type RowsProcessorBatchInterface interface {
+	// EndBatch is called after a batch with the given number of rows is written.
+	// It returns an error if the processor fails to complete the batch.
+	EndBatch(rows int64) error
+}
+
+// RowsProcessorContextInterface is implemented by processors that wait between the attempts of writing a batch,
+// for example to retry a batch failed with a transient error. RowsProcessor sets the context of the processing
+// before writing the rows, so the waiting stops when the processing is cancelled.
+type RowsProcessorContextInterface interface {
+	// SetContext sets the context that stops the waiting between the attempts when it is done.
+	// The batch being written is completed with its own timeout.
+	SetContext(ctx context.Context)
+}
+
+// RowsWriterInterface is implemented by processors that write the row values instead of SQL statements,
+// for example CSV or JSON Lines files. RowsProcessor calls WriteRows instead of Write for such processors
+// and does not build the INSERT statements if all processors write the row values.
+type RowsWriterInterface interface {
+	// WriteRows writes the provided rows with the given columns to the processor.
+	// It returns an error if the write operation fails.
+	WriteRows(columns []appdb.Column, rows [][]any) error
+}
+
+// RejectWriterInterface is implemented by the writers of the rows rejected by the destination,
+// which RowsProcessor uses with the dataset action ROW_ERROR_DEADLETTER.
+type RejectWriterInterface interface {
+	// WriteReject writes the given row with the given columns and the error the row was rejected with.
+	// It returns an error if the write operation fails.
+	WriteReject(columns []appdb.Column, row []any, reason error) error
+}
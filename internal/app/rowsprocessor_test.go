@@ -4,10 +4,14 @@
 package app
 
 import (
+	"context"
 	"copysqldatatool/internal/appdb"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -44,7 +48,7 @@ func prepareProcessor(t *testing.T, processor RowsProcessorInterface, rows int64
 	}
 
 	p := RowsProcessor{
-		Processor: processor,
+		Processors: []RowsProcessorInterface{processor},
 		DataReader: &appdb.DataReader{
 			AppDb:     &db,
 			Query:     SELECT_TBL_SQL,
@@ -103,7 +107,7 @@ func TestWriteFileRp1(t *testing.T) {
 	}
 	defer file.Close()
 	p := prepareProcessor(t, &FileProcessor{File: file}, 1)
-	err = p.Process()
+	err = p.Process(context.Background())
 	if err != nil {
 		fmt.Println(err)
 		assert.Fail(t, "error writing to file")
@@ -124,7 +128,7 @@ func TestWriteFileRp2(t *testing.T) {
 	}
 	defer file.Close()
 	p := prepareProcessor(t, &FileProcessor{File: file}, 2)
-	err = p.Process()
+	err = p.Process(context.Background())
 	if err != nil {
 		fmt.Println(err)
 		assert.Fail(t, "error writing to file")
@@ -139,7 +143,7 @@ func TestWriteFileRp2(t *testing.T) {
 func TestWriteDbRp(t *testing.T) {
 	p := prepareProcessor(t, &DbProcessor{AppDb: prepareDbRp(t), TableName: TBL_NAME_2}, 2)
 	p.Dataset.SqlStatementType = STATEMENT_TYPE_RAW
-	err := p.Process()
+	err := p.Process(context.Background())
 	if err != nil {
 		fmt.Println(err)
 		assert.Fail(t, "error writing to db")
@@ -154,7 +158,7 @@ func TestWriteDbRp(t *testing.T) {
 func TestWriteDbRpPrepared(t *testing.T) {
 	p := prepareProcessor(t, &DbProcessor{AppDb: prepareDbRp(t), TableName: TBL_NAME_2}, 2)
 	p.Dataset.SqlStatementType = STATEMENT_TYPE_PREPARED
-	err := p.Process()
+	err := p.Process(context.Background())
 	if err != nil {
 		fmt.Println(err)
 		assert.Fail(t, "error writing to db")
@@ -162,3 +166,489 @@ func TestWriteDbRpPrepared(t *testing.T) {
 	}
 	assert.Empty(t, err)
 }
+
+// testProcessor is a RowsProcessorInterface implementation for testing that stores written buffers
+// and returns the given error on write.
+type testProcessor struct {
+	err     error
+	buffers [][]string
+}
+
+// Write stores the buffer and returns the error of the test processor.
+func (p *testProcessor) Write(buffer []string, data []any) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.buffers = append(p.buffers, buffer)
+	return nil
+}
+
+// GetProcessedMsg returns a message for the test processor.
+func (p *testProcessor) GetProcessedMsg() string {
+	return "Rows processed to test processor"
+}
+
+// prepareWriteProcessor returns a RowsProcessor with a healthy and a failing test processor
+// and one statement in the buffer.
+func prepareWriteProcessor(onSinkError string) (*RowsProcessor, *testProcessor, *testProcessor) {
+	healthy := &testProcessor{}
+	failing := &testProcessor{err: fmt.Errorf("write error")}
+	p := RowsProcessor{
+		Processors: []RowsProcessorInterface{failing, healthy},
+		Dataset:    Dataset{OnSinkError: onSinkError},
+	}
+	p.reset()
+	p.buffer.AppendStr(INSERT_3)
+	return &p, healthy, failing
+}
+
+// TestWriteSinkErrorAbort verifies that the write method returns an error when a processor fails
+// and the OnSinkError of the dataset is SINK_ERROR_ABORT.
+func TestWriteSinkErrorAbort(t *testing.T) {
+	p, healthy, _ := prepareWriteProcessor(SINK_ERROR_ABORT)
+	err := p.write()
+	assert.NotNil(t, err)
+	assert.Empty(t, healthy.buffers)
+}
+
+// TestWriteSinkErrorContinue verifies that the write method continues with the healthy processor
+// when a processor fails and the OnSinkError of the dataset is SINK_ERROR_CONTINUE.
+// The failed processor is excluded from further writes and its error is returned at the end.
+func TestWriteSinkErrorContinue(t *testing.T) {
+	p, healthy, failing := prepareWriteProcessor(SINK_ERROR_CONTINUE)
+	err := p.write()
+	assert.Nil(t, err)
+	err = p.write()
+	assert.Nil(t, err)
+	assert.Len(t, healthy.buffers, 2)
+	assert.True(t, p.IsFailed(failing))
+	assert.False(t, p.IsFailed(healthy))
+	assert.NotNil(t, p.failedError())
+}
+
+// prepareSqliteDb creates a SQLite database with the given file name in a temporary directory with the tables
+// TBL_NAME and TBL_NAME_2 and inserts 3 rows into the table TBL_NAME. It returns the opened AppDb instance.
+// SQLite does not need a database server, so the tests with it can be run anywhere.
+func prepareSqliteDb(t *testing.T, fileName string) *appdb.AppDb {
+	db := appdb.AppDb{
+		Driver: appdb.DRIVER_SQLITE,
+		Dsn:    filepath.Join(t.TempDir(), fileName),
+	}
+	err := db.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	err = db.ExecMultiple("CREATE TABLE " + TBL_NAME + " (id INTEGER PRIMARY KEY, name TEXT);" +
+		"CREATE TABLE " + TBL_NAME_2 + " (id INTEGER PRIMARY KEY, name TEXT);" +
+		INSERT_INTO + TBL_NAME + " VALUES (1, 'a'), (2, 'b''c'), (3, NULL)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &db
+}
+
+// prepareSqliteProcessor initializes a RowsProcessor that copies the rows of the table TBL_NAME
+// of the source SQLite database to the table TBL_NAME_2 with the given number of rows per command.
+func prepareSqliteProcessor(src *appdb.AppDb, processor RowsProcessorInterface, rows int64) *RowsProcessor {
+	return &RowsProcessor{
+		Processors: []RowsProcessorInterface{processor},
+		DataReader: &appdb.DataReader{
+			AppDb:     &appdb.AppDb{Driver: src.Driver, Dsn: src.Dsn},
+			Query:     SELECT_FROM + TBL_NAME + " WHERE id > {{id}} ORDER BY id LIMIT 2",
+			QueryType: appdb.QUERY_TYPE_ORDERBYID,
+		},
+		Dataset: Dataset{
+			RowsPerCommand:   rows,
+			InsertCommand:    INSERT_INTO,
+			TableName:        TBL_NAME_2,
+			Driver:           src.Driver,
+			SqlStatementType: STATEMENT_TYPE_PREPARED,
+		},
+	}
+}
+
+// TestProcessSqlite verifies that the RowsProcessor copies all rows between SQLite tables
+// with prepared and raw statements, including NULL values and strings with quotes.
+func TestProcessSqlite(t *testing.T) {
+	for _, statementType := range []string{STATEMENT_TYPE_PREPARED, STATEMENT_TYPE_RAW} {
+		src := prepareSqliteDb(t, "src.db")
+		db := prepareSqliteDb(t, "dst.db")
+		p := prepareSqliteProcessor(src, &DbProcessor{AppDb: db, TableName: TBL_NAME_2}, 2)
+		p.Dataset.SqlStatementType = statementType
+		err := p.Process(context.Background())
+		assert.Nil(t, err)
+		count, err := db.GetScalar("SELECT COUNT(*) FROM " + TBL_NAME_2 + " WHERE name = 'b''c' OR name IS NULL OR id = 1")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), count)
+	}
+}
+
+// TestProcessSqliteTransaction verifies that with BatchesPerTransaction the progress is reported
+// only after the batches are committed and all rows are committed at the end of processing.
+func TestProcessSqliteTransaction(t *testing.T) {
+	src := prepareSqliteDb(t, "src.db")
+	db := prepareSqliteDb(t, "dst.db")
+	p := prepareSqliteProcessor(src, &DbProcessor{AppDb: db, TableName: TBL_NAME_2, BatchesPerTransaction: 2}, 1)
+	states := []appdb.ReaderState{}
+	p.OnBatchWritten.Subscribe(func(data any) {
+		states = append(states, data.(BatchProgress).State)
+	})
+	err := p.Process(context.Background())
+	assert.Nil(t, err)
+	assert.False(t, db.InTransaction())
+	if assert.Len(t, states, 1) {
+		assert.Equal(t, int64(2), states[0].LastId)
+	}
+	count, err := db.GetScalar("SELECT COUNT(*) FROM " + TBL_NAME_2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+// TestProcessSqliteDatasetTransaction verifies that with DatasetTransaction no progress is reported, all rows
+// are committed at the end of processing, and a failed processing rolls back all written rows.
+func TestProcessSqliteDatasetTransaction(t *testing.T) {
+	src := prepareSqliteDb(t, "src.db")
+	db := prepareSqliteDb(t, "dst.db")
+	p := prepareSqliteProcessor(src, &DbProcessor{AppDb: db, TableName: TBL_NAME_2, DatasetTransaction: true}, 1)
+	states := []appdb.ReaderState{}
+	p.OnBatchWritten.Subscribe(func(data any) {
+		states = append(states, data.(BatchProgress).State)
+	})
+	err := p.Process(context.Background())
+	assert.Nil(t, err)
+	assert.False(t, db.InTransaction())
+	assert.Empty(t, states)
+	count, err := db.GetScalar("SELECT COUNT(*) FROM " + TBL_NAME_2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+
+	// The row with id 3 and a NULL name fails the processing after the first 2 rows are written
+	src, db = prepareSqliteDeadLetter(t)
+	p = prepareSqliteProcessor(src, &DbProcessor{AppDb: db, TableName: TBL_NAME_2, DatasetTransaction: true}, 1)
+	err = p.Process(context.Background())
+	assert.ErrorContains(t, err, "NOT NULL constraint failed")
+	assert.False(t, db.InTransaction())
+	count, err = db.GetScalar("SELECT COUNT(*) FROM " + TBL_NAME_2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+// transactionProcessor is a test processor that writes the rows in a transaction.
+type transactionProcessor struct {
+	testProcessor
+	// True if there are written rows that are not committed yet.
+	inTransaction bool
+}
+
+// Write starts the transaction and writes the buffer like the test processor.
+func (p *transactionProcessor) Write(buffer []string, data []any) error {
+	p.inTransaction = true
+	return p.testProcessor.Write(buffer, data)
+}
+
+// InTransaction returns true if there are written rows that are not committed yet.
+func (p *transactionProcessor) InTransaction() bool {
+	return p.inTransaction
+}
+
+// Commit commits the written rows.
+func (p *transactionProcessor) Commit() error {
+	p.inTransaction = false
+	return nil
+}
+
+// Rollback discards the written rows.
+func (p *transactionProcessor) Rollback() error {
+	p.inTransaction = false
+	return nil
+}
+
+// TestWriteDeadLetterAborted verifies that the batch failed in a transaction of PostgreSQL is not split,
+// as the transaction is aborted, while the batch failed in a transaction of another database is split.
+func TestWriteDeadLetterAborted(t *testing.T) {
+	for driver, rejected := range map[string]int64{appdb.DRIVER_POSTGRES: 0, appdb.DRIVER_SQLITE: 2} {
+		processor := &transactionProcessor{testProcessor: testProcessor{err: fmt.Errorf("bad row")}}
+		p := RowsProcessor{
+			Processors: []RowsProcessorInterface{processor},
+			Dataset:    Dataset{Driver: driver, OnRowError: ROW_ERROR_DEADLETTER, InsertCommand: INSERT_INTO, TableName: TBL_NAME},
+			Rejects:    &RejectProcessor{Path: filepath.Join(t.TempDir(), "rejected.jsonl")},
+		}
+		p.reset()
+		p.rowColumns = []appdb.Column{{Name: "id"}}
+		p.columns = []string{"id"}
+		for id := range 2 {
+			p.rows = append(p.rows, []any{int64(id)})
+		}
+		p.buffer.AppendStr(INSERT_3)
+		err := p.write()
+		if rejected == 0 {
+			assert.ErrorContains(t, err, "bad row", driver)
+		} else {
+			assert.Nil(t, err, driver)
+		}
+		assert.Equal(t, rejected, p.GetRejectedCount(), driver)
+	}
+}
+
+// TestProcessSqliteCsv verifies that the RowsProcessor writes the header and the row values
+// to the CSV processor without building the INSERT statements.
+func TestProcessSqliteCsv(t *testing.T) {
+	src := prepareSqliteDb(t, "src.db")
+	file, err := os.Create(filepath.Join(t.TempDir(), "test.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	p := prepareSqliteProcessor(src, &CsvProcessor{File: file, NullValue: `\N`}, 2)
+	err = p.Process(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, p.buffer.Len())
+	data, err := os.ReadFile(file.Name())
+	assert.Nil(t, err)
+	assert.Equal(t, "id,name\n1,a\n2,b'c\n3,\\N\n", string(data))
+}
+
+// cancellingProcessor is a test processor that cancels the processing after the first written batch.
+type cancellingProcessor struct {
+	testProcessor
+	cancel context.CancelFunc
+}
+
+// Write stores the buffer and cancels the processing.
+func (p *cancellingProcessor) Write(buffer []string, data []any) error {
+	p.cancel()
+	return p.testProcessor.Write(buffer, data)
+}
+
+// TestProcessSqliteCancel verifies that the cancelled processing completes the batch being written,
+// reports its position and returns ErrCancelled before reading the next row.
+func TestProcessSqliteCancel(t *testing.T) {
+	src := prepareSqliteDb(t, "src.db")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	processor := &cancellingProcessor{cancel: cancel}
+	p := prepareSqliteProcessor(src, processor, 1)
+	states := []appdb.ReaderState{}
+	p.OnBatchWritten.Subscribe(func(data any) {
+		states = append(states, data.(BatchProgress).State)
+	})
+	err := p.Process(ctx)
+	assert.ErrorIs(t, err, ErrCancelled)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, processor.buffers, 1)
+	if assert.Len(t, states, 1) {
+		assert.Equal(t, int64(1), states[0].LastId)
+	}
+
+	processor = &cancellingProcessor{cancel: func() {}}
+	p = prepareSqliteProcessor(src, processor, 1)
+	err = p.Process(ctx)
+	assert.ErrorIs(t, err, ErrCancelled)
+	assert.Empty(t, processor.buffers)
+}
+
+// prepareSqliteDeadLetter creates the source database with 10 rows of the table TBL_NAME, every third of which
+// has a NULL name, and the destination database with the table TBL_NAME_2 that does not accept NULL names.
+func prepareSqliteDeadLetter(t *testing.T) (*appdb.AppDb, *appdb.AppDb) {
+	src := prepareSqliteDb(t, "src.db")
+	for id := 4; id <= 10; id++ {
+		name := fmt.Sprintf("'%d'", id)
+		if id%3 == 0 {
+			name = "NULL"
+		}
+		_, err := src.Exec(fmt.Sprintf("%s%s VALUES (%d, %s)", INSERT_INTO, TBL_NAME, id, name))
+		assert.Nil(t, err)
+	}
+	db := prepareSqliteDb(t, "dst.db")
+	err := db.ExecMultiple("DROP TABLE " + TBL_NAME_2 + ";CREATE TABLE " + TBL_NAME_2 + " (id INTEGER PRIMARY KEY, name TEXT NOT NULL)")
+	assert.Nil(t, err)
+	return src, db
+}
+
+// TestProcessSqliteDeadLetter verifies that the batches rejected because of bad rows are split
+// until the bad rows are found, which are written to the rejected rows, while the other rows are written.
+func TestProcessSqliteDeadLetter(t *testing.T) {
+	for _, statementType := range []string{STATEMENT_TYPE_PREPARED, STATEMENT_TYPE_RAW} {
+		src, db := prepareSqliteDeadLetter(t)
+		// The rows are written in transactions as by the SQLite destinations
+		p := prepareSqliteProcessor(src, &DbProcessor{AppDb: db, TableName: TBL_NAME_2, BatchesPerTransaction: 2}, 4)
+		p.DataReader.Query = SELECT_FROM + TBL_NAME + " WHERE id > {{id}} ORDER BY id LIMIT 5"
+		p.Dataset.SqlStatementType = statementType
+		p.Dataset.OnRowError = ROW_ERROR_DEADLETTER
+		rejects := &RejectProcessor{Path: filepath.Join(t.TempDir(), "rejected.jsonl")}
+		p.Rejects = rejects
+		err := p.Process(context.Background())
+		assert.Nil(t, err)
+		assert.Nil(t, rejects.Close())
+		assert.Equal(t, int64(10), p.GetRowsCount())
+		assert.Equal(t, int64(3), p.GetRejectedCount())
+		count, err := db.GetScalar("SELECT COUNT(*) FROM " + TBL_NAME_2)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(7), count)
+		data, err := os.ReadFile(rejects.Path)
+		assert.Nil(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if assert.Len(t, lines, 3) {
+			assert.Contains(t, lines[0], `"row":{"id":3,"name":null}`)
+			assert.Contains(t, lines[0], "NOT NULL constraint failed")
+			assert.Contains(t, lines[2], `"row":{"id":9,"name":null}`)
+		}
+	}
+}
+
+// TestProcessSqliteDeadLetterMaxRejects verifies that the processing fails when the number of rejected rows
+// exceeds the max number, and that without the dead letter action the first bad row fails the processing.
+func TestProcessSqliteDeadLetterMaxRejects(t *testing.T) {
+	src, db := prepareSqliteDeadLetter(t)
+	p := prepareSqliteProcessor(src, &DbProcessor{AppDb: db, TableName: TBL_NAME_2}, 4)
+	p.Dataset.OnRowError = ROW_ERROR_DEADLETTER
+	p.Rejects = &RejectProcessor{Path: filepath.Join(t.TempDir(), "rejected.jsonl"), MaxRejects: 2}
+	err := p.Process(context.Background())
+	assert.ErrorIs(t, err, ErrTooManyRejects)
+	assert.Equal(t, int64(2), p.GetRejectedCount())
+
+	p = prepareSqliteProcessor(src, &DbProcessor{AppDb: db, TableName: TBL_NAME_2}, 4)
+	err = p.Process(context.Background())
+	assert.NotNil(t, err)
+	assert.NotErrorIs(t, err, ErrTooManyRejects)
+	assert.Equal(t, int64(0), p.GetRejectedCount())
+}
+
+// TestProcessSqliteMaxBatchBytes verifies that the batch is written before the row that would exceed
+// the max size in bytes, and that the reported position includes only the rows of the written batches.
+func TestProcessSqliteMaxBatchBytes(t *testing.T) {
+	for _, statementType := range []string{STATEMENT_TYPE_PREPARED, STATEMENT_TYPE_RAW} {
+		src := prepareSqliteDb(t, "src.db")
+		for id := 4; id <= 10; id++ {
+			_, err := src.Exec(fmt.Sprintf("%s%s VALUES (%d, '%s')", INSERT_INTO, TBL_NAME, id, strings.Repeat("x", 20)))
+			assert.Nil(t, err)
+		}
+		processor := &testProcessor{}
+		p := prepareSqliteProcessor(src, processor, 100)
+		p.DataReader.Query = SELECT_FROM + TBL_NAME + " WHERE id > {{id}} ORDER BY id LIMIT 100"
+		p.Dataset.SqlStatementType = statementType
+		p.Dataset.MaxBatchBytes = 150
+		states := []appdb.ReaderState{}
+		p.OnBatchWritten.Subscribe(func(data any) {
+			states = append(states, data.(BatchProgress).State)
+		})
+		err := p.Process(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(10), p.GetRowsCount())
+		assert.Greater(t, len(processor.buffers), 2, statementType)
+		// The position after the last batch is saved as completed
+		assert.Len(t, states, len(processor.buffers)-1)
+		written := int64(0)
+		for i, buffer := range processor.buffers {
+			assert.LessOrEqual(t, len(strings.Join(buffer, "")), 150, statementType)
+			// The buffer has the INSERT command, the rows and the terminating semicolon
+			written += int64(len(buffer) - 2)
+			if i < len(states) {
+				assert.Equal(t, written, states[i].LastId, statementType)
+			}
+		}
+		assert.Equal(t, int64(10), written)
+	}
+}
+
+// TestIsPlaceholdersFull verifies that a prepared statement is full when the next row would exceed
+// the max number of placeholders of the destination, and raw statements are not limited.
+func TestIsPlaceholdersFull(t *testing.T) {
+	p := prepareSqliteProcessor(&appdb.AppDb{Driver: appdb.DRIVER_SQLITE}, &testProcessor{}, 100000)
+	p.reset()
+	p.data = make([]any, 32764)
+	assert.False(t, p.isPlaceholdersFull(2))
+	p.data = append(p.data, 1)
+	assert.True(t, p.isPlaceholdersFull(2))
+	p.Dataset.SqlStatementType = STATEMENT_TYPE_RAW
+	assert.False(t, p.isPlaceholdersFull(2))
+}
+
+// TestAppendRowReducedRows verifies that the batch is flushed by the next row when the rows per command
+// have been reduced below the rows of the batch, as the writer of the pipeline does, and that it is not
+// reported as full, so its latency does not adjust the rows again.
+func TestAppendRowReducedRows(t *testing.T) {
+	processor := &testProcessor{}
+	p := prepareSqliteProcessor(&appdb.AppDb{Driver: appdb.DRIVER_SQLITE}, processor, 10)
+	p.reset()
+	p.adaptive = &AdaptiveRows{}
+	p.adaptive.Start(10)
+	for id := range 5 {
+		assert.Nil(t, p.appendRow(context.Background(), []any{int64(id), "a"}, appdb.ReaderState{}, p.DataReader.GetState))
+	}
+	assert.Empty(t, processor.buffers)
+	p.rowsPerCommand.Store(2)
+	assert.Nil(t, p.appendRow(context.Background(), []any{int64(5), "a"}, appdb.ReaderState{}, p.DataReader.GetState))
+	if assert.Len(t, processor.buffers, 1) {
+		assert.Len(t, processor.buffers[0], 8)
+	}
+	assert.Equal(t, int64(2), p.rowsPerCommand.Load())
+	assert.Equal(t, int64(0), p.count)
+}
+
+// TestProcessSqlitePlaceholders verifies that the rows of one batch with more values than SQLite
+// accepts in one prepared statement are written in several statements.
+func TestProcessSqlitePlaceholders(t *testing.T) {
+	src := prepareSqliteDb(t, "src.db")
+	_, err := src.Exec("WITH RECURSIVE ids(id) AS (SELECT 4 UNION ALL SELECT id + 1 FROM ids WHERE id < 20000) " +
+		INSERT_INTO + TBL_NAME + " SELECT id, 'name' FROM ids")
+	assert.Nil(t, err)
+	db := prepareSqliteDb(t, "dst.db")
+	p := prepareSqliteProcessor(src, &DbProcessor{AppDb: db, TableName: TBL_NAME_2}, 20000)
+	p.DataReader.Query = SELECT_FROM + TBL_NAME + " WHERE id > {{id}} ORDER BY id LIMIT 20000"
+	err = p.Process(context.Background())
+	assert.Nil(t, err)
+	count, err := db.GetScalar("SELECT COUNT(*) FROM " + TBL_NAME_2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(20000), count)
+}
+
+// slowProcessor is a test processor that takes the given time to write each batch.
+type slowProcessor struct {
+	testProcessor
+	delay time.Duration
+}
+
+// Write waits for the delay and stores the buffer.
+func (p *slowProcessor) Write(buffer []string, data []any) error {
+	time.Sleep(p.delay)
+	return p.testProcessor.Write(buffer, data)
+}
+
+// TestProcessSqliteAdaptiveRows verifies that the rows per command are halved while the batches
+// are written slower than the target latency and grow while they are written faster.
+func TestProcessSqliteAdaptiveRows(t *testing.T) {
+	src := prepareSqliteDb(t, "src.db")
+	_, err := src.Exec("WITH RECURSIVE ids(id) AS (SELECT 4 UNION ALL SELECT id + 1 FROM ids WHERE id < 40) " +
+		INSERT_INTO + TBL_NAME + " SELECT id, 'name' FROM ids")
+	assert.Nil(t, err)
+
+	slow := &slowProcessor{delay: 20 * time.Millisecond}
+	p := prepareSqliteProcessor(src, slow, 16)
+	p.DataReader.Query = SELECT_FROM + TBL_NAME + " WHERE id > {{id}} ORDER BY id LIMIT 100"
+	p.Dataset.AdaptiveRows = true
+	p.Dataset.MinRowsPerCommand = 2
+	p.Dataset.TargetLatency = 10 * time.Millisecond
+	err = p.Process(context.Background())
+	assert.Nil(t, err)
+	sizes := []int{}
+	for _, buffer := range slow.buffers {
+		sizes = append(sizes, len(buffer)-2)
+	}
+	assert.Equal(t, []int{16, 8, 4, 2, 2, 2, 2, 2, 2}, sizes)
+
+	fast := &testProcessor{}
+	p = prepareSqliteProcessor(src, fast, 10)
+	p.DataReader.Query = SELECT_FROM + TBL_NAME + " WHERE id > {{id}} ORDER BY id LIMIT 100"
+	p.Dataset.AdaptiveRows = true
+	p.Dataset.MaxRowsPerCommand = 11
+	err = p.Process(context.Background())
+	assert.Nil(t, err)
+	sizes = []int{}
+	for _, buffer := range fast.buffers {
+		sizes = append(sizes, len(buffer)-2)
+	}
+	assert.Equal(t, []int{10, 11, 11, 8}, sizes)
+	assert.Equal(t, int64(11), p.rowsPerCommand.Load())
+}
@@ -0,0 +1,224 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"copysqldatatool/internal/appdb"
+	"copysqldatatool/internal/appfile"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SplitManifest represents the manifest file of the output split into several files.
+type SplitManifest struct {
+	// Total number of rows in all parts
+	Rows int64 `json:"rows"`
+	// Parts in the order they were written
+	Parts []appfile.Part `json:"parts"`
+}
+
+// SplitProcessor writes SQL statements to a sequence of part files.
+// A part is completed after the batch that reaches MaxRows or MaxBytes, so the parts are always
+// cut on statement boundaries. The next part is created when the next batch is written.
+// MaxBytes cannot limit the processors that keep the rows in memory until the end of the part,
+// for example Parquet, so it is not used with them.
+// Finish completes the last part and writes the manifest with the row count and the checksum of each part.
+// See: SplitRowsProcessor for the processors that write the row values.
+type SplitProcessor struct {
+	// Max number of rows in one part. If 0, the number of rows is not limited.
+	MaxRows int64
+	// Max size of one part file in bytes. If 0, the size is not limited.
+	MaxBytes int64
+	// Path of the manifest file written by Finish. If empty, the manifest is not written.
+	ManifestPath string
+	// CreatePart creates the output file and the processor of the part with the given number starting from 1.
+	// If appending is true, the existing part file is truncated to the given size and appended.
+	CreatePart func(number int, appending bool, size int64) (RowsProcessorInterface, SplitFileInterface, error)
+	// Completed parts.
+	parts []appfile.Part
+	// Processor of the current part, nil if there is no current part.
+	processor RowsProcessorInterface
+	// File of the current part, nil if there is no current part.
+	file SplitFileInterface
+	// Number of rows written to the current part.
+	rows int64
+}
+
+// SplitRowsProcessor writes row values to a sequence of part files.
+// It is used with the processors that write the row values, for example CSV or Parquet files.
+// See: SplitProcessor
+type SplitRowsProcessor struct {
+	*SplitProcessor
+}
+
+// Resume restores the parts saved by GetParts. The parts with a checksum are completed,
+// the last part without a checksum is reopened and appended from its saved size.
+// It returns an error if the current part cannot be reopened.
+func (sp *SplitProcessor) Resume(parts []appfile.Part) error {
+	sp.parts = make([]appfile.Part, 0, len(parts))
+	for _, part := range parts {
+		if part.Sha256 == "" {
+			return sp.openPart(part.Rows > 0, part.Bytes, part.Rows)
+		}
+		sp.parts = append(sp.parts, part)
+	}
+	return nil
+}
+
+// Write writes the given buffer and data to the processor of the current part.
+// The part is created if there is no current part.
+// See: app.RowsProcessorInterface.Write
+func (sp *SplitProcessor) Write(buffer []string, data []any) error {
+	if err := sp.openPart(false, 0, 0); err != nil {
+		return err
+	}
+	return sp.processor.Write(buffer, data)
+}
+
+// WriteRows writes the given rows to the processor of the current part.
+// The part is created if there is no current part.
+// See: app.RowsWriterInterface.WriteRows
+func (sp *SplitRowsProcessor) WriteRows(columns []appdb.Column, rows [][]any) error {
+	if err := sp.openPart(false, 0, 0); err != nil {
+		return err
+	}
+	rowsWriter, ok := sp.processor.(RowsWriterInterface)
+	if !ok {
+		return fmt.Errorf("part processor does not write rows")
+	}
+	return rowsWriter.WriteRows(columns, rows)
+}
+
+// EndBatch adds the given number of rows to the current part and completes the part
+// if it has reached MaxRows or MaxBytes. With MaxBytes the data buffered by the compressing writer
+// is flushed to the part file first, so a part exceeds MaxBytes by one batch at most.
+// See: app.RowsProcessorBatchInterface.EndBatch
+func (sp *SplitProcessor) EndBatch(rows int64) error {
+	if sp.file == nil {
+		return nil
+	}
+	sp.rows += rows
+	if sp.MaxRows > 0 && sp.rows >= sp.MaxRows {
+		return sp.closePart()
+	}
+	if sp.MaxBytes > 0 {
+		if err := sp.file.Flush(); err != nil {
+			return err
+		}
+		size, err := sp.file.Size()
+		if err != nil {
+			return err
+		}
+		if size >= sp.MaxBytes {
+			return sp.closePart()
+		}
+	}
+	return nil
+}
+
+// Finish completes the current part and writes the manifest file.
+// See: app.RowsProcessorFinishInterface.Finish
+func (sp *SplitProcessor) Finish() error {
+	if sp.file != nil {
+		if err := sp.closePart(); err != nil {
+			return err
+		}
+	}
+	if sp.ManifestPath == "" {
+		return nil
+	}
+	manifest := SplitManifest{Parts: sp.parts}
+	for _, part := range sp.parts {
+		manifest.Rows += part.Rows
+	}
+	data, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(sp.ManifestPath, data, 0644)
+}
+
+// Close closes the file of the current part without completing it.
+// It is used to release the file when the processing has failed.
+func (sp *SplitProcessor) Close() error {
+	if sp.file == nil {
+		return nil
+	}
+	err := sp.file.Close()
+	sp.file = nil
+	sp.processor = nil
+	return err
+}
+
+// GetParts returns the completed parts and the current part.
+// The current part has no checksum, its size is the size of the file on disk.
+// It returns an error if the size of the current part cannot be read.
+func (sp *SplitProcessor) GetParts() ([]appfile.Part, error) {
+	parts := append(make([]appfile.Part, 0, len(sp.parts)+1), sp.parts...)
+	if sp.file == nil {
+		return parts, nil
+	}
+	size, err := sp.file.Size()
+	if err != nil {
+		return nil, err
+	}
+	return append(parts, appfile.Part{File: filepath.Base(sp.file.Name()), Rows: sp.rows, Bytes: size}), nil
+}
+
+// openPart creates the next part if there is no current part.
+// If appending is true, the part file is appended from the given size with the given number of rows.
+func (sp *SplitProcessor) openPart(appending bool, size int64, rows int64) error {
+	if sp.file != nil {
+		return nil
+	}
+	if sp.CreatePart == nil {
+		return fmt.Errorf("part creation is not set")
+	}
+	processor, file, err := sp.CreatePart(len(sp.parts)+1, appending, size)
+	if err != nil {
+		return err
+	}
+	sp.processor = processor
+	sp.file = file
+	sp.rows = rows
+	return nil
+}
+
+// closePart finishes the processor of the current part, closes the part file and adds it to the completed parts.
+func (sp *SplitProcessor) closePart() error {
+	if finishProcessor, ok := sp.processor.(RowsProcessorFinishInterface); ok {
+		if err := finishProcessor.Finish(); err != nil {
+			return err
+		}
+	}
+	if err := sp.file.Close(); err != nil {
+		return err
+	}
+	size, err := sp.file.Size()
+	if err != nil {
+		return err
+	}
+	sp.parts = append(sp.parts, appfile.Part{
+		File:   filepath.Base(sp.file.Name()),
+		Rows:   sp.rows,
+		Bytes:  size,
+		Sha256: sp.file.Sha256(),
+	})
+	sp.file = nil
+	sp.processor = nil
+	sp.rows = 0
+	return nil
+}
+
+// GetProcessedMsg returns a message indicating the number of rows processed
+// to the part files and the number of completed parts.
+// See: app.RowsProcessorInterface.GetProcessedMsg
+func (sp *SplitProcessor) GetProcessedMsg() string {
+	if sp.file != nil {
+		return fmt.Sprint("Rows processed to file: ", sp.file.Name(), " completed parts: ", len(sp.parts))
+	}
+	return fmt.Sprint("Rows processed to file parts: ", len(sp.parts))
+}
@@ -0,0 +1,165 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"copysqldatatool/internal/appdb"
+	"copysqldatatool/internal/appfile"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// prepareSplitProcessor returns a SplitProcessor that writes the parts to a temporary directory
+// with the processors created by the given function.
+func prepareSplitProcessor(t *testing.T, maxRows int64, createProcessor func(file *appfile.AppFile, appending bool) RowsProcessorInterface) (*SplitProcessor, string) {
+	dir := t.TempDir()
+	split := &SplitProcessor{
+		MaxRows:      maxRows,
+		ManifestPath: filepath.Join(dir, "table.manifest.json"),
+		CreatePart: func(number int, appending bool, size int64) (RowsProcessorInterface, SplitFileInterface, error) {
+			file := &appfile.AppFile{Path: filepath.Join(dir, fmt.Sprintf("table.%04d.sql", number))}
+			var err error
+			if appending {
+				err = file.Resume(size)
+			} else {
+				err = file.Create()
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			return createProcessor(file, appending), file, nil
+		},
+	}
+	t.Cleanup(func() { split.Close() })
+	return split, dir
+}
+
+// writeSplitBatch writes one statement with one row to the SplitProcessor and ends the batch.
+func writeSplitBatch(t *testing.T, split *SplitProcessor, id int) {
+	assert.Nil(t, split.Write([]string{fmt.Sprintf("INSERT INTO table VALUES (%d);", id)}, nil))
+	assert.Nil(t, split.EndBatch(1))
+}
+
+// TestSplitProcessor verifies that the statements are split into parts with the given number of rows
+// and the manifest lists each part with its row count and SHA-256 checksum.
+func TestSplitProcessor(t *testing.T) {
+	split, dir := prepareSplitProcessor(t, 2, func(file *appfile.AppFile, appending bool) RowsProcessorInterface {
+		return &FileProcessor{File: file}
+	})
+	for id := 1; id <= 5; id++ {
+		writeSplitBatch(t, split, id)
+	}
+	assert.Nil(t, split.Finish())
+
+	data, err := os.ReadFile(split.ManifestPath)
+	assert.Nil(t, err)
+	manifest := SplitManifest{}
+	assert.Nil(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, int64(5), manifest.Rows)
+	if assert.Len(t, manifest.Parts, 3) {
+		assert.Equal(t, "table.0001.sql", manifest.Parts[0].File)
+		assert.Equal(t, int64(2), manifest.Parts[0].Rows)
+		assert.Equal(t, int64(1), manifest.Parts[2].Rows)
+		content, err := os.ReadFile(filepath.Join(dir, "table.0003.sql"))
+		assert.Nil(t, err)
+		assert.Equal(t, "INSERT INTO table VALUES (5);\n", string(content))
+		checksum := sha256.Sum256(content)
+		assert.Equal(t, hex.EncodeToString(checksum[:]), manifest.Parts[2].Sha256)
+		assert.Equal(t, int64(len(content)), manifest.Parts[2].Bytes)
+	}
+}
+
+// TestSplitProcessorMaxBytesCompressed verifies that the compressed parts are flushed before their size
+// is compared with MaxBytes, so the output is split although the compressing writer buffers the data.
+func TestSplitProcessorMaxBytesCompressed(t *testing.T) {
+	dir := t.TempDir()
+	split := &SplitProcessor{
+		MaxBytes:     40,
+		ManifestPath: filepath.Join(dir, "table.manifest.json"),
+		CreatePart: func(number int, appending bool, size int64) (RowsProcessorInterface, SplitFileInterface, error) {
+			file := &appfile.AppFile{
+				Path:        filepath.Join(dir, fmt.Sprintf("table.%04d.sql.gz", number)),
+				Compression: appfile.COMPRESSION_GZIP,
+			}
+			if err := file.Create(); err != nil {
+				return nil, nil, err
+			}
+			return &FileProcessor{File: file}, file, nil
+		},
+	}
+	defer split.Close()
+	for id := 1; id <= 5; id++ {
+		writeSplitBatch(t, split, id)
+	}
+	assert.Nil(t, split.Finish())
+
+	parts, err := split.GetParts()
+	assert.Nil(t, err)
+	assert.Greater(t, len(parts), 1)
+	rows := int64(0)
+	for i, part := range parts {
+		if i < len(parts)-1 {
+			assert.GreaterOrEqual(t, part.Bytes, split.MaxBytes, part.File)
+		}
+		rows += part.Rows
+	}
+	assert.Equal(t, int64(5), rows)
+}
+
+// TestSplitProcessorResume verifies that the split output is continued from the saved parts:
+// the completed parts are kept and the current part is truncated to its saved size and appended.
+func TestSplitProcessorResume(t *testing.T) {
+	createProcessor := func(file *appfile.AppFile, appending bool) RowsProcessorInterface {
+		return &FileProcessor{File: file}
+	}
+	split, dir := prepareSplitProcessor(t, 2, createProcessor)
+	for id := 1; id <= 3; id++ {
+		writeSplitBatch(t, split, id)
+	}
+	parts, err := split.GetParts()
+	assert.Nil(t, err)
+	writeSplitBatch(t, split, 4)
+	split.Close()
+
+	resumed := &SplitProcessor{MaxRows: 2, ManifestPath: split.ManifestPath, CreatePart: split.CreatePart}
+	defer resumed.Close()
+	assert.Nil(t, resumed.Resume(parts))
+	writeSplitBatch(t, resumed, 4)
+	writeSplitBatch(t, resumed, 5)
+	assert.Nil(t, resumed.Finish())
+
+	content, err := os.ReadFile(filepath.Join(dir, "table.0002.sql"))
+	assert.Nil(t, err)
+	assert.Equal(t, "INSERT INTO table VALUES (3);\nINSERT INTO table VALUES (4);\n", string(content))
+	parts, err = resumed.GetParts()
+	assert.Nil(t, err)
+	if assert.Len(t, parts, 3) {
+		checksum := sha256.Sum256(content)
+		assert.Equal(t, hex.EncodeToString(checksum[:]), parts[1].Sha256)
+	}
+}
+
+// TestSplitRowsProcessor verifies that each part of the row values output starts with the CSV header.
+func TestSplitRowsProcessor(t *testing.T) {
+	split, dir := prepareSplitProcessor(t, 1, func(file *appfile.AppFile, appending bool) RowsProcessorInterface {
+		return &CsvProcessor{File: file, SkipHeader: appending}
+	})
+	p := SplitRowsProcessor{SplitProcessor: split}
+	columns := []appdb.Column{{Name: "id"}}
+	for id := 1; id <= 2; id++ {
+		assert.Nil(t, p.WriteRows(columns, [][]any{{id}}))
+		assert.Nil(t, p.EndBatch(1))
+	}
+	assert.Nil(t, p.Finish())
+	content, err := os.ReadFile(filepath.Join(dir, "table.0002.sql"))
+	assert.Nil(t, err)
+	assert.Equal(t, "id\n2\n", string(content))
+}
@@ -0,0 +1,116 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"sort"
+	"sync"
+)
+
+// PoolTask is a task run by the WorkerPool.
+type PoolTask struct {
+	// Priority of the task. Tasks with a higher priority are started first,
+	// tasks with the same priority are started in the order they were added.
+	Priority int
+	// Number of connections used by the task by connection name, for example {"source": 1, "dest": 1}.
+	Connections map[string]int
+	// Run runs the task.
+	Run func()
+}
+
+// WorkerPool runs tasks in a bounded number of goroutines.
+// A task is started when a worker is free and the connections it needs are available,
+// so the number of open connections never exceeds the limit of each connection.
+// The tasks are started in the order of their priority, a task waiting for connections
+// holds back the tasks with a lower priority.
+type WorkerPool struct {
+	// Number of workers. If 0, each task has its own worker.
+	Workers int
+	// Max number of connections used by the running tasks by connection name.
+	// Connections without a limit or with the limit 0 are not limited.
+	Limits map[string]int
+	// Number of connections used by the running tasks by connection name.
+	used map[string]int
+	// mutex is used to synchronize access to the used connections.
+	mutex sync.Mutex
+	// cond signals that connections have been released.
+	cond *sync.Cond
+}
+
+// Run runs the given tasks and waits for them to complete.
+func (wp *WorkerPool) Run(tasks []PoolTask) {
+	queue := make([]PoolTask, len(tasks))
+	copy(queue, tasks)
+	sort.SliceStable(queue, func(i, j int) bool {
+		return queue[i].Priority > queue[j].Priority
+	})
+	wp.used = map[string]int{}
+	wp.cond = sync.NewCond(&wp.mutex)
+
+	workers := wp.Workers
+	if workers <= 0 || workers > len(queue) {
+		workers = len(queue)
+	}
+	// The tasks are taken from the queue in order, the next task is taken
+	// only after the connections of the previous one have been acquired
+	next := make(chan PoolTask)
+	go func() {
+		defer close(next)
+		for _, task := range queue {
+			wp.acquire(task.Connections)
+			next <- task
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range next {
+				task.Run()
+				wp.release(task.Connections)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// acquire waits until the given connections are available and marks them as used.
+// A task that needs more connections than the limit waits until no other task uses the connection.
+func (wp *WorkerPool) acquire(connections map[string]int) {
+	wp.mutex.Lock()
+	defer wp.mutex.Unlock()
+	for !wp.available(connections) {
+		wp.cond.Wait()
+	}
+	for name, count := range connections {
+		wp.used[name] += count
+	}
+}
+
+// release marks the given connections as unused and wakes up the waiting task.
+func (wp *WorkerPool) release(connections map[string]int) {
+	wp.mutex.Lock()
+	defer wp.mutex.Unlock()
+	for name, count := range connections {
+		wp.used[name] -= count
+	}
+	wp.cond.Broadcast()
+}
+
+// available returns true if the given connections can be used without exceeding the limits.
+// The caller must hold the mutex.
+func (wp *WorkerPool) available(connections map[string]int) bool {
+	for name, count := range connections {
+		limit := wp.Limits[name]
+		if limit <= 0 || count <= 0 || wp.used[name] == 0 {
+			continue
+		}
+		if wp.used[name]+count > limit {
+			return false
+		}
+	}
+	return true
+}
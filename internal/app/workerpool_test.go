@@ -0,0 +1,104 @@
+// Description: This package provides management features for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package app
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// poolCounter counts the running tasks and records the max number of running tasks
+// and used connections.
+type poolCounter struct {
+	mutex   sync.Mutex
+	running int
+	maxRun  int
+	used    map[string]int
+	maxUsed map[string]int
+	order   []int
+}
+
+// task returns a pool task with the given number that records its run in the counter.
+func (pc *poolCounter) task(number int, priority int, connections map[string]int) PoolTask {
+	return PoolTask{
+		Priority:    priority,
+		Connections: connections,
+		Run: func() {
+			pc.mutex.Lock()
+			pc.order = append(pc.order, number)
+			pc.running++
+			pc.maxRun = max(pc.maxRun, pc.running)
+			for name, count := range connections {
+				pc.used[name] += count
+				pc.maxUsed[name] = max(pc.maxUsed[name], pc.used[name])
+			}
+			pc.mutex.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			pc.mutex.Lock()
+			pc.running--
+			for name, count := range connections {
+				pc.used[name] -= count
+			}
+			pc.mutex.Unlock()
+		},
+	}
+}
+
+// TestWorkerPoolWorkers verifies that the WorkerPool runs all tasks with no more than
+// the given number of workers.
+func TestWorkerPoolWorkers(t *testing.T) {
+	counter := &poolCounter{used: map[string]int{}, maxUsed: map[string]int{}}
+	tasks := []PoolTask{}
+	for i := range 10 {
+		tasks = append(tasks, counter.task(i, 0, nil))
+	}
+	pool := WorkerPool{Workers: 3}
+	pool.Run(tasks)
+	assert.Len(t, counter.order, 10)
+	assert.Equal(t, 3, counter.maxRun)
+
+	counter = &poolCounter{used: map[string]int{}, maxUsed: map[string]int{}}
+	tasks = []PoolTask{}
+	for i := range 5 {
+		tasks = append(tasks, counter.task(i, 0, nil))
+	}
+	pool = WorkerPool{}
+	pool.Run(tasks)
+	assert.Equal(t, 5, counter.maxRun)
+}
+
+// TestWorkerPoolPriority verifies that the WorkerPool starts the tasks in the order of their priority
+// and keeps the order of the tasks with the same priority.
+func TestWorkerPoolPriority(t *testing.T) {
+	counter := &poolCounter{used: map[string]int{}, maxUsed: map[string]int{}}
+	pool := WorkerPool{Workers: 1}
+	pool.Run([]PoolTask{
+		counter.task(1, 0, nil),
+		counter.task(2, 10, nil),
+		counter.task(3, -1, nil),
+		counter.task(4, 10, nil),
+		counter.task(5, 0, nil),
+	})
+	assert.Equal(t, []int{2, 4, 1, 5, 3}, counter.order)
+}
+
+// TestWorkerPoolLimits verifies that the WorkerPool does not exceed the connection limits
+// and runs a task that needs more connections than the limit alone.
+func TestWorkerPoolLimits(t *testing.T) {
+	counter := &poolCounter{used: map[string]int{}, maxUsed: map[string]int{}}
+	tasks := []PoolTask{}
+	for i := range 8 {
+		tasks = append(tasks, counter.task(i, 0, map[string]int{"source": 1, "dest": 2}))
+	}
+	tasks = append(tasks, counter.task(8, 0, map[string]int{"source": 5}))
+	pool := WorkerPool{Workers: 10, Limits: map[string]int{"source": 3, "dest": 4}}
+	pool.Run(tasks)
+	assert.Len(t, counter.order, 9)
+	assert.Equal(t, 4, counter.maxUsed["dest"])
+	assert.LessOrEqual(t, counter.maxUsed["source"], 5)
+	assert.Equal(t, 2, counter.maxRun)
+}
@@ -7,21 +7,41 @@ import (
 	"copysqldatatool/internal/appdb"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
+	"slices"
 	"strings"
 )
 
 // Constants for configuration types and states.
 const (
-	QUERY_TYPE_UNDEFINED    = ""
-	QUERY_TYPE_SIMPLE       = "simple"
-	QUERY_TYPE_LIMIT_OFFSET = "limitoffset"
-	QUERY_TYPE_ORDERBYID    = "orderbyid"
-	QUERY_TYPE_BETWEEN      = "between"
-	STATEMENT_PREPARED      = "prepared"
-	STATEMENT_RAW           = "raw"
-	COPY_TO_FILE            = "file"
-	COPY_TO_DB              = "db"
+	QUERY_TYPE_UNDEFINED     = ""
+	QUERY_TYPE_SIMPLE        = "simple"
+	QUERY_TYPE_LIMIT_OFFSET  = "limitoffset"
+	QUERY_TYPE_ORDERBYID     = "orderbyid"
+	QUERY_TYPE_BETWEEN       = "between"
+	STATEMENT_PREPARED       = "prepared"
+	STATEMENT_RAW            = "raw"
+	COPY_TO_FILE             = "file"
+	COPY_TO_DB               = "db"
+	SINK_ERROR_ABORT         = "abort"
+	SINK_ERROR_CONTINUE      = "continue"
+	ROW_ERROR_ABORT          = "abort"
+	ROW_ERROR_DEADLETTER     = "deadletter"
+	TRANSACTION_MODE_BATCHES = "batches"
+	TRANSACTION_MODE_DATASET = "dataset"
+	WRITE_METHOD_INSERT      = "insert"
+	WRITE_METHOD_LOAD_DATA   = "load_data"
+	WRITE_METHOD_NATIVE      = "native"
+	FILE_FORMAT_SQL          = "sql"
+	FILE_FORMAT_CSV          = "csv"
+	FILE_FORMAT_TSV          = "tsv"
+	FILE_FORMAT_JSONL        = "jsonl"
+	FILE_FORMAT_PARQUET      = "parquet"
+	OUTPUT_OVERWRITE         = "overwrite"
+	OUTPUT_FAIL              = "fail"
+	OUTPUT_SUFFIX            = "suffix"
+	OUTPUT_FILE_TEMPLATE     = "{{table}}"
 )
 
 // Config represents the root configuration structure
@@ -37,6 +57,8 @@ type ConfigMain struct {
 	Source         DBConfig      `json:"source"`
 	Dest           DBConfig      `json:"dest"`
 	DefaultDataset DefaultConfig `json:"default_dataset"`
+	// Max number of datasets processed at the same time. 0 means no limit
+	MaxConcurrency int `json:"max_concurrency"`
 }
 
 // DBConfig contains database connection details
@@ -44,6 +66,8 @@ type DBConfig struct {
 	Description string `json:"description"`
 	Driver      string `json:"driver"`
 	DSN         string `json:"dsn"`
+	// Max number of connections opened at the same time by the datasets. 0 means no limit
+	MaxConnections int `json:"max_connections"`
 }
 
 // DefaultConfig contains default dataset configuration
@@ -57,6 +81,82 @@ type DefaultConfig struct {
 	ExecutionTime int64  `json:"execution_time"`
 	// Limit for query type "limitoffset"
 	Limit int64 `json:"limit"`
+	// Names of the key columns for query type "orderbyid"
+	KeyColumns []string `json:"key_columns"`
+	// Target number of rows in one range for query type "between" with the range column
+	BetweenChunkRows int64 `json:"between_chunk_rows"`
+	// Source of the number of rows for query type "between" with the range column: "count" or "estimate"
+	BetweenRowCount string `json:"between_row_count"`
+	// Number of partitions processed in parallel for query types "between" and "orderbyid"
+	Parallelism int `json:"parallelism"`
+	// Max time in seconds of a source query and of the wait for each next row
+	QueryTimeout int64 `json:"query_timeout"`
+	// Max time in seconds of writing one batch to the destination database
+	WriteTimeout int64 `json:"write_timeout"`
+	// Max time in seconds of processing the dataset
+	DatasetTimeout int64 `json:"dataset_timeout"`
+	// Kill the query on the database server when it exceeds the timeout
+	KillQueryOnTimeout bool `json:"kill_query_on_timeout"`
+	// Max number of attempts of a query or a batch failed with a transient error
+	RetryMaxAttempts int `json:"retry_max_attempts"`
+	// Delay in milliseconds before the first retry
+	RetryBaseDelay int64 `json:"retry_base_delay_ms"`
+	// Max delay in milliseconds between retries
+	RetryMaxDelay int64 `json:"retry_max_delay_ms"`
+	// Action when one of the destinations fails: "abort" or "continue"
+	OnSinkError string `json:"on_sink_error"`
+	// Action when the destination database rejects a batch because of bad rows: "abort" or "deadletter"
+	OnRowError string `json:"on_row_error"`
+	// Max number of rejected rows for "deadletter"
+	MaxRejects int64 `json:"max_rejects"`
+	// Number of connections to the destination database writing the batches in parallel
+	Writers int `json:"writers"`
+	// Number of batches written in one transaction of the destination database
+	TransactionBatches int64 `json:"transaction_batches"`
+	// Transaction mode of the destination database: "batches" or "dataset"
+	TransactionMode string `json:"transaction_mode"`
+	// Method of writing the rows to the destination database: "insert", "load_data" or "native"
+	WriteMethod string `json:"write_method"`
+	// Settings of the INSERT queries of the "native" write method
+	InsertSettings map[string]any `json:"insert_settings"`
+	// Read, format and write the rows in parallel
+	Pipeline bool `json:"pipeline"`
+	// Number of batches formatted ahead of the writer for "pipeline"
+	PipelineDepth int `json:"pipeline_depth"`
+	// Adjust the rows per command to the write latency of the batches
+	AdaptiveRows bool `json:"adaptive_rows"`
+	// Min rows per command for "adaptive_rows"
+	AdaptiveMinRows int64 `json:"adaptive_min_rows"`
+	// Max rows per command for "adaptive_rows"
+	AdaptiveMaxRows int64 `json:"adaptive_max_rows"`
+	// Target write latency in milliseconds of one batch for "adaptive_rows"
+	AdaptiveTargetLatency int64 `json:"adaptive_target_latency_ms"`
+	// Max size in bytes of one INSERT statement
+	MaxBatchBytes int64 `json:"max_batch_bytes"`
+	// Limit the size of one INSERT statement by max_allowed_packet of the destination
+	ReadMaxAllowedPacket bool `json:"read_max_allowed_packet"`
+	// Format of the output file: "sql", "csv", "tsv", "jsonl" or "parquet"
+	FileFormat string `json:"file_format"`
+	// Field delimiter for file formats "csv" and "tsv"
+	CsvDelimiter string `json:"csv_delimiter"`
+	// String written for NULL values for file formats "csv" and "tsv"
+	CsvNull string `json:"csv_null"`
+	// Number of rows in one row group for file format "parquet"
+	ParquetRowGroupSize int64 `json:"parquet_row_group_size"`
+	// Compression of the output file: "", "gzip", "zstd" or "lz4"
+	Compression string `json:"compression"`
+	// Compression level of the output file
+	CompressionLevel int `json:"compression_level"`
+	// Max number of rows in one output file
+	MaxRowsPerFile int64 `json:"max_rows_per_file"`
+	// Max size of one output file in bytes
+	MaxBytesPerFile int64 `json:"max_bytes_per_file"`
+	// Directory of the output files
+	OutputDir string `json:"output_dir"`
+	// Template of the output file name without the extension
+	OutputFileTemplate string `json:"output_file_template"`
+	// Action when the output file exists: "overwrite", "fail" or "suffix"
+	OutputOverwrite string `json:"output_overwrite"`
 }
 
 // Dataset represents a query and its target table
@@ -82,6 +182,13 @@ type Dataset struct {
 	MaxOffset int64 `json:"max_offset"`
 	// Initial Id for query type "orderbyid"
 	InitialId int64 `json:"initial_id"`
+	// Names of the key columns for query type "orderbyid" in the order of the ORDER BY clause.
+	// The last read values of the key columns replace the {{key.<name>}} placeholders
+	// and the {{key}} placeholder is replaced by the condition that selects the rows after the last key
+	KeyColumns []string `json:"key_columns"`
+	// Initial values of the key columns for query type "orderbyid" as SQL literals by column name,
+	// for example {"id": "0", "name": "''"}. Required if the query contains the {{key.<name>}} placeholders
+	InitialKey map[string]string `json:"initial_key"`
 	// Initial value in BETWEEN condition for query type "between"
 	// Number or date string in format 'YYYY-MM-DD HH:MM:SS'
 	BetweenStart string `json:"between_start"`
@@ -91,14 +198,113 @@ type Dataset struct {
 	// Step between initial and final values in BETWEEN condition  for query type "between"
 	// Number or date string in format of range type '2h30m15s'
 	BetweenStep string `json:"between_step"`
+	// Column of the range for query type "between". If set, the start, end and step values that are not set
+	// are calculated from the minimum and maximum values of the column in the source table
+	BetweenColumn string `json:"between_column"`
+	// Target number of rows in one range for query type "between" with the range column
+	BetweenChunkRows int64 `json:"between_chunk_rows"`
+	// Source of the number of rows for query type "between" with the range column: "count" or "estimate".
+	// "count" counts the rows, "estimate" uses the table statistics if the database provides them.
+	// Empty value means "count"
+	BetweenRowCount string `json:"between_row_count"`
+	// Number of partitions processed in parallel for query types "between" and "orderbyid".
+	// Each partition has its own source and destination connection. 0 or 1 means no partitions
+	Parallelism int `json:"parallelism"`
+	// Priority of the dataset. Datasets with a higher priority are started first,
+	// datasets with the same priority are started in the order of the config
+	Priority int `json:"priority"`
+	// Max time in seconds of a source query and of the wait for each next row. 0 means no limit
+	QueryTimeout int64 `json:"query_timeout"`
+	// Max time in seconds of writing one batch to the destination database. 0 means no limit
+	WriteTimeout int64 `json:"write_timeout"`
+	// Max time in seconds of processing the dataset. The dataset is stopped like a cancelled one
+	// and reported as a timeout. 0 means no limit
+	DatasetTimeout int64 `json:"dataset_timeout"`
+	// Kill the query on the database server when it exceeds the query or write timeout,
+	// for example with KILL QUERY in MySQL, so the server stops executing it
+	KillQueryOnTimeout bool `json:"kill_query_on_timeout"`
+	// Max number of attempts of a source query or a destination batch failed with a transient error,
+	// for example a deadlock or a lost connection. 0 or 1 means no retries
+	RetryMaxAttempts int `json:"retry_max_attempts"`
+	// Delay in milliseconds before the first retry, it is doubled for each next retry. 0 means 1000
+	RetryBaseDelay int64 `json:"retry_base_delay_ms"`
+	// Max delay in milliseconds between retries. 0 means 60000
+	RetryMaxDelay int64 `json:"retry_max_delay_ms"`
 	// SQL script to be executed before inserting data. For example, disabling indexes
 	OnInsertSessionStart string `json:"on_insert_session_start"`
 	// SQL script to be executed after inserting data. For example, enabling indexes
 	OnInsertSessionEnd string `json:"on_insert_session_end"`
+	// Action when one of the destinations fails: "abort" or "continue"
+	// "abort" stops the dataset, "continue" keeps writing to the destinations that have not failed
+	OnSinkError string `json:"on_sink_error"`
+	// Action when the destination database rejects a batch because of bad rows: "abort" or "deadletter".
+	// "deadletter" splits the failed batch in halves until the bad rows are found, writes them to
+	// <table>.rejected.jsonl in the output directory and writes the other rows. Empty value means "abort"
+	OnRowError string `json:"on_row_error"`
+	// Max number of rejected rows for "deadletter", the dataset fails when it is exceeded. 0 means no limit
+	MaxRejects int64 `json:"max_rejects"`
+	// Number of connections to the destination database writing the batches in parallel, each connection
+	// runs the session scripts. Supported only for copy to db. 0 means 1
+	Writers int `json:"writers"`
+	// Number of batches written in one transaction of the destination database for "batches" transaction mode.
+	// 0 means autocommit mode for each batch, except SQLite, where it means 100
+	TransactionBatches int64 `json:"transaction_batches"`
+	// Transaction mode of the destination database: "batches" or "dataset". Empty value means "batches".
+	// "dataset" writes all batches in one transaction committed when the dataset is completed
+	TransactionMode string `json:"transaction_mode"`
+	// Method of writing the rows to the destination database: "insert", "load_data" or "native". Empty value means "insert".
+	// "load_data" streams the rows of each batch to MySQL with LOAD DATA LOCAL INFILE,
+	// "native" sends each batch to ClickHouse with the native batch API
+	WriteMethod string `json:"write_method"`
+	// Settings of the INSERT queries of the "native" write method, for example {"async_insert": 1, "insert_quorum": 2}
+	InsertSettings map[string]any `json:"insert_settings"`
+	// Read, format and write the rows in parallel goroutines, so the source is read while the batches are written
+	Pipeline bool `json:"pipeline"`
+	// Number of batches formatted ahead of the writer for "pipeline". 0 means 2
+	PipelineDepth int `json:"pipeline_depth"`
+	// Adjust the rows per command to the write latency of the batches, starting from "rows".
+	// The rows grow by a step while the batches are written faster than the target latency and are halved when slower
+	AdaptiveRows bool `json:"adaptive_rows"`
+	// Min rows per command for "adaptive_rows". 0 means 1
+	AdaptiveMinRows int64 `json:"adaptive_min_rows"`
+	// Max rows per command for "adaptive_rows". 0 means 10 times "rows"
+	AdaptiveMaxRows int64 `json:"adaptive_max_rows"`
+	// Target write latency in milliseconds of one batch for "adaptive_rows". 0 means 1000
+	AdaptiveTargetLatency int64 `json:"adaptive_target_latency_ms"`
+	// Max size in bytes of one INSERT statement with the values of the prepared statement.
+	// The batch is written before the row that would exceed it. 0 means no limit
+	MaxBatchBytes int64 `json:"max_batch_bytes"`
+	// Read max_allowed_packet of the MySQL destination before the dataset and use it as the max size of one INSERT statement
+	ReadMaxAllowedPacket bool `json:"read_max_allowed_packet"`
+	// Format of the output file: "sql", "csv", "tsv", "jsonl" or "parquet". Empty value means "sql"
+	FileFormat string `json:"file_format"`
+	// Field delimiter for file formats "csv" and "tsv". Empty value means "," for "csv" and tab for "tsv"
+	CsvDelimiter string `json:"csv_delimiter"`
+	// String written for NULL values for file formats "csv" and "tsv". Empty value means an empty field
+	CsvNull string `json:"csv_null"`
+	// Number of rows in one row group for file format "parquet". 0 means 100000 rows
+	ParquetRowGroupSize int64 `json:"parquet_row_group_size"`
+	// Compression of the output file: "", "gzip", "zstd" or "lz4". Empty value means no compression
+	Compression string `json:"compression"`
+	// Compression level of the output file. 0 means the default level of the compression
+	CompressionLevel int `json:"compression_level"`
+	// Max number of rows in one output file. If set, the output is split into several files. 0 means no limit
+	MaxRowsPerFile int64 `json:"max_rows_per_file"`
+	// Max size of one output file in bytes. If set, the output is split into several files. 0 means no limit
+	MaxBytesPerFile int64 `json:"max_bytes_per_file"`
+	// Directory of the output files. It is created if it does not exist. Empty value means the current directory
+	OutputDir string `json:"output_dir"`
+	// Template of the output file name without the extension. Empty value means "{{table}}"
+	// Placeholders: {{table}}, {{dataset_index}}, {{date}}, {{datetime}} and {{run_id}}
+	OutputFileTemplate string `json:"output_file_template"`
+	// Action when the output file exists: "overwrite", "fail" or "suffix". Empty value means "overwrite"
+	// "suffix" adds the first free number suffix "_1", "_2", ... to the file name
+	OutputOverwrite string `json:"output_overwrite"`
 }
 
 // Validate checks the configuration for required fields and returns an error if any are missing.
-// It verifies that the source and destination database drivers and DSNs are not empty.
+// It verifies that the source and destination database drivers and DSNs are not empty
+// and that the settings of the datasets with a fixed set of values have known values.
 // If any validation rules are violated, it returns an error with a message for each issue found.
 func (config *Config) Validate() error {
 	messages := []string{}
@@ -114,6 +320,9 @@ func (config *Config) Validate() error {
 	if config.Config.Dest.DSN == "" {
 		messages = append(messages, "destination database DSN cannot be empty")
 	}
+	for i := range config.Datasets {
+		messages = append(messages, config.Datasets[i].validate()...)
+	}
 
 	if len(messages) > 0 {
 		return errors.New(strings.Join(messages, "\n"))
@@ -202,6 +411,176 @@ func (config *Config) fillDataset(i int) {
 	if config.Datasets[i].Limit == 0 {
 		config.Datasets[i].Limit = config.Config.DefaultDataset.Limit
 	}
+	if len(config.Datasets[i].KeyColumns) == 0 {
+		config.Datasets[i].KeyColumns = config.Config.DefaultDataset.KeyColumns
+	}
+	if config.Datasets[i].BetweenChunkRows == 0 {
+		config.Datasets[i].BetweenChunkRows = config.Config.DefaultDataset.BetweenChunkRows
+	}
+	if config.Datasets[i].BetweenRowCount == "" {
+		config.Datasets[i].BetweenRowCount = config.Config.DefaultDataset.BetweenRowCount
+	}
+	if config.Datasets[i].Parallelism == 0 {
+		config.Datasets[i].Parallelism = config.Config.DefaultDataset.Parallelism
+	}
+	if config.Datasets[i].QueryTimeout == 0 {
+		config.Datasets[i].QueryTimeout = config.Config.DefaultDataset.QueryTimeout
+	}
+	if config.Datasets[i].WriteTimeout == 0 {
+		config.Datasets[i].WriteTimeout = config.Config.DefaultDataset.WriteTimeout
+	}
+	if config.Datasets[i].DatasetTimeout == 0 {
+		config.Datasets[i].DatasetTimeout = config.Config.DefaultDataset.DatasetTimeout
+	}
+	if !config.Datasets[i].KillQueryOnTimeout {
+		config.Datasets[i].KillQueryOnTimeout = config.Config.DefaultDataset.KillQueryOnTimeout
+	}
+	if config.Datasets[i].RetryMaxAttempts == 0 {
+		config.Datasets[i].RetryMaxAttempts = config.Config.DefaultDataset.RetryMaxAttempts
+	}
+	if config.Datasets[i].RetryBaseDelay == 0 {
+		config.Datasets[i].RetryBaseDelay = config.Config.DefaultDataset.RetryBaseDelay
+	}
+	if config.Datasets[i].RetryMaxDelay == 0 {
+		config.Datasets[i].RetryMaxDelay = config.Config.DefaultDataset.RetryMaxDelay
+	}
+	if config.Datasets[i].OnSinkError == "" {
+		config.Datasets[i].OnSinkError = config.Config.DefaultDataset.OnSinkError
+	}
+	if config.Datasets[i].OnRowError == "" {
+		config.Datasets[i].OnRowError = config.Config.DefaultDataset.OnRowError
+	}
+	if config.Datasets[i].MaxRejects == 0 {
+		config.Datasets[i].MaxRejects = config.Config.DefaultDataset.MaxRejects
+	}
+	if config.Datasets[i].Writers == 0 {
+		config.Datasets[i].Writers = config.Config.DefaultDataset.Writers
+	}
+	if config.Datasets[i].TransactionBatches == 0 {
+		config.Datasets[i].TransactionBatches = config.Config.DefaultDataset.TransactionBatches
+	}
+	if config.Datasets[i].TransactionMode == "" {
+		config.Datasets[i].TransactionMode = config.Config.DefaultDataset.TransactionMode
+	}
+	if config.Datasets[i].WriteMethod == "" {
+		config.Datasets[i].WriteMethod = config.Config.DefaultDataset.WriteMethod
+	}
+	if config.Datasets[i].InsertSettings == nil {
+		config.Datasets[i].InsertSettings = config.Config.DefaultDataset.InsertSettings
+	}
+	if !config.Datasets[i].Pipeline {
+		config.Datasets[i].Pipeline = config.Config.DefaultDataset.Pipeline
+	}
+	if config.Datasets[i].PipelineDepth == 0 {
+		config.Datasets[i].PipelineDepth = config.Config.DefaultDataset.PipelineDepth
+	}
+	if !config.Datasets[i].AdaptiveRows {
+		config.Datasets[i].AdaptiveRows = config.Config.DefaultDataset.AdaptiveRows
+	}
+	if config.Datasets[i].AdaptiveMinRows == 0 {
+		config.Datasets[i].AdaptiveMinRows = config.Config.DefaultDataset.AdaptiveMinRows
+	}
+	if config.Datasets[i].AdaptiveMaxRows == 0 {
+		config.Datasets[i].AdaptiveMaxRows = config.Config.DefaultDataset.AdaptiveMaxRows
+	}
+	if config.Datasets[i].AdaptiveTargetLatency == 0 {
+		config.Datasets[i].AdaptiveTargetLatency = config.Config.DefaultDataset.AdaptiveTargetLatency
+	}
+	if config.Datasets[i].MaxBatchBytes == 0 {
+		config.Datasets[i].MaxBatchBytes = config.Config.DefaultDataset.MaxBatchBytes
+	}
+	if !config.Datasets[i].ReadMaxAllowedPacket {
+		config.Datasets[i].ReadMaxAllowedPacket = config.Config.DefaultDataset.ReadMaxAllowedPacket
+	}
+	if config.Datasets[i].FileFormat == "" {
+		config.Datasets[i].FileFormat = config.Config.DefaultDataset.FileFormat
+	}
+	if config.Datasets[i].CsvDelimiter == "" {
+		config.Datasets[i].CsvDelimiter = config.Config.DefaultDataset.CsvDelimiter
+	}
+	if config.Datasets[i].CsvNull == "" {
+		config.Datasets[i].CsvNull = config.Config.DefaultDataset.CsvNull
+	}
+	if config.Datasets[i].ParquetRowGroupSize == 0 {
+		config.Datasets[i].ParquetRowGroupSize = config.Config.DefaultDataset.ParquetRowGroupSize
+	}
+	if config.Datasets[i].Compression == "" {
+		config.Datasets[i].Compression = config.Config.DefaultDataset.Compression
+	}
+	if config.Datasets[i].CompressionLevel == 0 {
+		config.Datasets[i].CompressionLevel = config.Config.DefaultDataset.CompressionLevel
+	}
+	if config.Datasets[i].MaxRowsPerFile == 0 {
+		config.Datasets[i].MaxRowsPerFile = config.Config.DefaultDataset.MaxRowsPerFile
+	}
+	if config.Datasets[i].MaxBytesPerFile == 0 {
+		config.Datasets[i].MaxBytesPerFile = config.Config.DefaultDataset.MaxBytesPerFile
+	}
+	if config.Datasets[i].OutputDir == "" {
+		config.Datasets[i].OutputDir = config.Config.DefaultDataset.OutputDir
+	}
+	if config.Datasets[i].OutputFileTemplate == "" {
+		config.Datasets[i].OutputFileTemplate = config.Config.DefaultDataset.OutputFileTemplate
+	}
+	if config.Datasets[i].OutputOverwrite == "" {
+		config.Datasets[i].OutputOverwrite = config.Config.DefaultDataset.OutputOverwrite
+	}
+}
+
+// GetFileFormat returns the format of the output file of the dataset.
+// If the format is not set, it returns FILE_FORMAT_SQL.
+func (ds *Dataset) GetFileFormat() string {
+	if ds.FileFormat == "" {
+		return FILE_FORMAT_SQL
+	}
+	return ds.FileFormat
+}
+
+// GetOutputFileTemplate returns the template of the output file name of the dataset.
+// If the template is not set, it returns OUTPUT_FILE_TEMPLATE.
+func (ds *Dataset) GetOutputFileTemplate() string {
+	if ds.OutputFileTemplate == "" {
+		return OUTPUT_FILE_TEMPLATE
+	}
+	return ds.OutputFileTemplate
+}
+
+// GetOutputOverwrite returns the action of the dataset when the output file exists.
+// If the action is not set, it returns OUTPUT_OVERWRITE.
+func (ds *Dataset) GetOutputOverwrite() string {
+	if ds.OutputOverwrite == "" {
+		return OUTPUT_OVERWRITE
+	}
+	return ds.OutputOverwrite
+}
+
+// validate returns a message for each setting of the dataset that has an unknown value.
+// An empty value means the default of the setting.
+func (ds *Dataset) validate() []string {
+	messages := []string{}
+	messages = ds.checkValue(messages, "transaction_mode", ds.TransactionMode, TRANSACTION_MODE_BATCHES, TRANSACTION_MODE_DATASET)
+	messages = ds.checkValue(messages, "on_sink_error", ds.OnSinkError, SINK_ERROR_ABORT, SINK_ERROR_CONTINUE)
+	messages = ds.checkValue(messages, "on_row_error", ds.OnRowError, ROW_ERROR_ABORT, ROW_ERROR_DEADLETTER)
+	messages = ds.checkValue(messages, "write_method", ds.WriteMethod, WRITE_METHOD_INSERT, WRITE_METHOD_LOAD_DATA, WRITE_METHOD_NATIVE)
+	// Parquet row groups are kept in memory and written at once, so the size of the part cannot be limited.
+	if ds.MaxBytesPerFile > 0 && ds.GetFileFormat() == FILE_FORMAT_PARQUET {
+		messages = append(messages, fmt.Sprintf("dataset %s: max_bytes_per_file is not supported for parquet files, use max_rows_per_file", ds.Table))
+	}
+	return messages
+}
+
+// checkValue appends a message to the given messages if the given value of the setting with the given name
+// is not empty and is not one of the given allowed values.
+func (ds *Dataset) checkValue(messages []string, name string, value string, allowed ...string) []string {
+	if value == "" || slices.Contains(allowed, value) {
+		return messages
+	}
+	return append(messages, fmt.Sprintf("dataset %s: unknown %s %q, expected one of: %s", ds.Table, name, value, strings.Join(allowed, ", ")))
+}
+
+// SplitEnabled returns true if the output file of the dataset is split into several files, false otherwise.
+func (ds *Dataset) SplitEnabled() bool {
+	return ds.MaxRowsPerFile > 0 || ds.MaxBytesPerFile > 0
 }
 
 // CopyToDbEnabled returns true if the dataset is set to copy data to a database, false otherwise.
@@ -50,3 +50,74 @@ func TestEmptyTable(t *testing.T) {
 	expected := "db.test"
 	assert.Equal(t, expected, config.Datasets[0].Table)
 }
+
+// TestValidateTransactionMode verifies that an unknown transaction mode of a dataset is rejected,
+// while the known modes and the empty mode are accepted.
+func TestValidateTransactionMode(t *testing.T) {
+	config := Config{}
+	assert.Nil(t, config.LoadConfigFromString(configJSON))
+	for _, mode := range []string{"", TRANSACTION_MODE_BATCHES, TRANSACTION_MODE_DATASET} {
+		config.Datasets[0].TransactionMode = mode
+		assert.Nil(t, config.Validate(), mode)
+	}
+	for _, mode := range []string{"Dataset", "datasets"} {
+		config.Datasets[0].TransactionMode = mode
+		assert.ErrorContains(t, config.Validate(), "unknown transaction_mode", mode)
+	}
+}
+
+// TestValidateWriteMethod verifies that an unknown write method of a dataset is rejected,
+// while the known methods and the empty method are accepted.
+func TestValidateWriteMethod(t *testing.T) {
+	config := Config{}
+	assert.Nil(t, config.LoadConfigFromString(configJSON))
+	for _, method := range []string{"", WRITE_METHOD_INSERT, WRITE_METHOD_LOAD_DATA, WRITE_METHOD_NATIVE} {
+		config.Datasets[0].WriteMethod = method
+		assert.Nil(t, config.Validate(), method)
+	}
+	for _, method := range []string{"loaddata", "load-data"} {
+		config.Datasets[0].WriteMethod = method
+		assert.ErrorContains(t, config.Validate(), "unknown write_method", method)
+	}
+}
+
+// TestValidateOnRowError verifies that an unknown action on row errors of a dataset is rejected,
+// while the known actions and the empty action are accepted.
+func TestValidateOnRowError(t *testing.T) {
+	config := Config{}
+	assert.Nil(t, config.LoadConfigFromString(configJSON))
+	for _, action := range []string{"", ROW_ERROR_ABORT, ROW_ERROR_DEADLETTER} {
+		config.Datasets[0].OnRowError = action
+		assert.Nil(t, config.Validate(), action)
+	}
+	config.Datasets[0].OnRowError = "dead_letter"
+	assert.ErrorContains(t, config.Validate(), "unknown on_row_error")
+}
+
+// TestValidateOnSinkError verifies that an unknown action on sink errors of a dataset is rejected,
+// while the known actions and the empty action are accepted.
+func TestValidateOnSinkError(t *testing.T) {
+	config := Config{}
+	assert.Nil(t, config.LoadConfigFromString(configJSON))
+	for _, action := range []string{"", SINK_ERROR_ABORT, SINK_ERROR_CONTINUE} {
+		config.Datasets[0].OnSinkError = action
+		assert.Nil(t, config.Validate(), action)
+	}
+	config.Datasets[0].OnSinkError = "Continue"
+	assert.ErrorContains(t, config.Validate(), "unknown on_sink_error")
+}
+
+func TestValidateMaxBytesPerFile(t *testing.T) {
+	config := Config{}
+	assert.Nil(t, config.LoadConfigFromString(configJSON))
+	config.Datasets[0].MaxBytesPerFile = 1000
+	for _, format := range []string{"", FILE_FORMAT_SQL, FILE_FORMAT_CSV, FILE_FORMAT_JSONL} {
+		config.Datasets[0].FileFormat = format
+		assert.Nil(t, config.Validate(), format)
+	}
+	config.Datasets[0].FileFormat = FILE_FORMAT_PARQUET
+	assert.ErrorContains(t, config.Validate(), "max_bytes_per_file is not supported for parquet files")
+	config.Datasets[0].MaxBytesPerFile = 0
+	config.Datasets[0].MaxRowsPerFile = 1000
+	assert.Nil(t, config.Validate())
+}
@@ -4,14 +4,20 @@
 package appdb
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	_ "github.com/ClickHouse/clickhouse-go/v2"
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
-// Constants for query types and statement types.
+// Constants for query types, statement types and database drivers.
 const (
 	QUERY_TYPE_UNDEFINED    = ""
 	QUERY_TYPE_SIMPLE       = "simple"
@@ -21,6 +27,14 @@ const (
 	STATEMENT_TYPE_PREPARED = "prepared"
 	STATEMENT_TYPE_RAW      = "raw"
 	DATE_TIME_LAYOUT        = "2006-01-02 15:04:05"
+	DRIVER_MYSQL            = "mysql"
+	DRIVER_CLICKHOUSE       = "clickhouse"
+	DRIVER_POSTGRES         = "postgres"
+	DRIVER_SQLITE           = "sqlite"
+	ROW_COUNT_COUNT         = "count"
+	ROW_COUNT_ESTIMATE      = "estimate"
+	// Max time of the statement that kills the query after a timeout
+	KILL_QUERY_TIMEOUT = 10 * time.Second
 )
 
 // AppDb represents a database connection configuration and handle.
@@ -32,8 +46,40 @@ type AppDb struct {
 	Driver string
 	// Dsn is the data source name for the database connection.
 	Dsn string
+	// KillQueryOnTimeout enables stopping the query on the database server when the context of the query
+	// is cancelled by a timeout. The statements are executed on one pinned connection and the query
+	// is killed from another connection, for example with KILL QUERY in MySQL.
+	// It is supported by the databases whose dialect provides the connection id.
+	KillQueryOnTimeout bool
 	// db is the underlying SQL database connection.
 	db *sql.DB
+	// conn is the pinned connection used if KillQueryOnTimeout is set, nil otherwise.
+	conn *sql.Conn
+	// connectionId is the id of the pinned connection on the database server.
+	connectionId int64
+	// tx is the active transaction. If nil, statements are executed in autocommit mode.
+	tx *sql.Tx
+}
+
+// sqlExecutor is the common interface of sql.DB and sql.Tx used to execute statements
+// inside or outside of a transaction.
+type sqlExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// executor returns the active transaction if there is one, otherwise the pinned connection
+// if there is one, otherwise the database connection.
+func (appdb *AppDb) executor() sqlExecutor {
+	if appdb.tx != nil {
+		return appdb.tx
+	}
+	if appdb.conn != nil {
+		return appdb.conn
+	}
+	return appdb.db
 }
 
 // Open opens a database connection with the database driver and data source name (DSN)
@@ -48,10 +94,55 @@ func (appdb *AppDb) Open() error {
 		return err
 	}
 	appdb.db = db
+	if appdb.KillQueryOnTimeout && appdb.GetDialect().ConnectionId() != "" {
+		if err = appdb.pinConnection(); err != nil {
+			appdb.Close()
+			return err
+		}
+	}
+	return nil
+}
+
+// pinConnection reserves one connection of the database connection pool for all statements
+// and reads its id, which is used to kill the query running on it.
+func (appdb *AppDb) pinConnection() error {
+	conn, err := appdb.db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	appdb.conn = conn
+	var connectionId int64
+	err = conn.QueryRowContext(context.Background(), appdb.GetDialect().ConnectionId()).Scan(&connectionId)
+	if err != nil {
+		return fmt.Errorf("error reading connection id: %w", err)
+	}
+	appdb.connectionId = connectionId
 	return nil
 }
 
+// killOnTimeout kills the query running on the pinned connection from another connection when
+// the given context is cancelled by a timeout, so the database server stops executing it.
+// It returns the function that stops watching the context, which must be called after the statement
+// or the reading of the query rows has completed. Errors of the kill statement are ignored,
+// as the statement fails anyway with the timeout error.
+func (appdb *AppDb) killOnTimeout(ctx context.Context) func() bool {
+	if appdb.conn == nil || ctx.Done() == nil {
+		return func() bool { return false }
+	}
+	db := appdb.db
+	kill := appdb.GetDialect().KillQuery(appdb.connectionId)
+	return context.AfterFunc(ctx, func() {
+		if !errors.Is(context.Cause(ctx), ErrTimeout) {
+			return
+		}
+		killCtx, cancel := context.WithTimeout(context.Background(), KILL_QUERY_TIMEOUT)
+		defer cancel()
+		db.ExecContext(killCtx, kill)
+	})
+}
+
 // Close closes the database connection if it is open.
+// An active transaction is rolled back before closing.
 // It sets the underlying SQL database connection to nil after closing.
 // If the connection is already closed, it does nothing.
 // Returns an error if the operation fails.
@@ -59,6 +150,11 @@ func (appdb *AppDb) Close() error {
 	if appdb.db == nil {
 		return nil
 	}
+	appdb.Rollback()
+	if appdb.conn != nil {
+		appdb.conn.Close()
+		appdb.conn = nil
+	}
 	err := appdb.db.Close()
 	if err != nil {
 		return err
@@ -67,19 +163,106 @@ func (appdb *AppDb) Close() error {
 	return nil
 }
 
+// Reopen closes the database connection and opens it again, it is used to recover from a lost connection.
+// An active transaction is rolled back. It returns an error if the connection cannot be opened.
+func (appdb *AppDb) Reopen() error {
+	appdb.Close()
+	return appdb.Open()
+}
+
+// GetDialect returns the SQL dialect for the database driver of the AppDb instance.
+func (appdb *AppDb) GetDialect() DialectInterface {
+	dialectFactory := DialectFactory{}
+	return dialectFactory.CreateDialect(appdb.Driver)
+}
+
+// GetMaxStatementSize returns the max size in bytes of one statement the database server accepts,
+// for example max_allowed_packet of MySQL. It returns 0 if the database does not limit it.
+func (appdb *AppDb) GetMaxStatementSize() (int64, error) {
+	query := appdb.GetDialect().MaxStatementSize()
+	if query == "" {
+		return 0, nil
+	}
+	value, err := appdb.GetScalar(query)
+	if err != nil {
+		return 0, err
+	}
+	size, ok := toRangeInt(value)
+	if !ok {
+		return 0, fmt.Errorf("invalid max statement size: %v", value)
+	}
+	return size, nil
+}
+
 // IsOpen checks if the database connection is currently open.
 // It returns true if the connection is open, otherwise it returns false.
 func (appdb *AppDb) IsOpen() bool {
 	return appdb.db != nil
 }
 
+// Begin starts a transaction on the database connection.
+// All statements executed by the AppDb instance are executed in the transaction until Commit or Rollback is called.
+// It returns an error if a transaction is already active or the transaction cannot be started.
+func (appdb *AppDb) Begin() error {
+	if appdb.tx != nil {
+		return fmt.Errorf("transaction is already active")
+	}
+	var tx *sql.Tx
+	var err error
+	if appdb.conn != nil {
+		tx, err = appdb.conn.BeginTx(context.Background(), nil)
+	} else {
+		tx, err = appdb.db.Begin()
+	}
+	if err != nil {
+		return err
+	}
+	appdb.tx = tx
+	return nil
+}
+
+// Commit commits the active transaction. If there is no active transaction, it does nothing.
+// It returns an error if the commit fails.
+func (appdb *AppDb) Commit() error {
+	if appdb.tx == nil {
+		return nil
+	}
+	err := appdb.tx.Commit()
+	appdb.tx = nil
+	return err
+}
+
+// Rollback rolls back the active transaction. If there is no active transaction, it does nothing.
+// It returns an error if the rollback fails.
+func (appdb *AppDb) Rollback() error {
+	if appdb.tx == nil {
+		return nil
+	}
+	err := appdb.tx.Rollback()
+	appdb.tx = nil
+	return err
+}
+
+// InTransaction returns true if there is an active transaction.
+func (appdb *AppDb) InTransaction() bool {
+	return appdb.tx != nil
+}
+
 // Exec executes a SQL statement with the given data on the database connection.
 // The method returns a Result instance if the execution is successful, otherwise it returns an error.
 // The Result instance provides information about the number of affected rows and the last inserted ID.
 func (appdb *AppDb) Exec(sqlCommand string, args ...any) (sql.Result, error) {
-	result, err := appdb.db.Exec(sqlCommand, args...)
+	return appdb.ExecContext(context.Background(), sqlCommand, args...)
+}
+
+// ExecContext executes a SQL statement with the given data on the database connection.
+// The execution is cancelled when the given context is done.
+// See: AppDb.Exec
+func (appdb *AppDb) ExecContext(ctx context.Context, sqlCommand string, args ...any) (sql.Result, error) {
+	defer appdb.killOnTimeout(ctx)()
+	result, err := appdb.executor().ExecContext(ctx, sqlCommand, args...)
 	if err != nil {
-		return nil, err
+		return nil, TimeoutError(ctx, err)
 	}
 	return result, nil
 }
@@ -91,15 +274,23 @@ func (appdb *AppDb) Exec(sqlCommand string, args ...any) (sql.Result, error) {
 // using the Exec method. If any statement results in an error, it returns
 // the error. Otherwise it returns nil.
 func (appdb *AppDb) ExecMultiple(sqlCommands string) error {
+	return appdb.ExecMultipleContext(context.Background(), sqlCommands)
+}
+
+// ExecMultipleContext executes multiple SQL statements separated by semicolons on the database connection.
+// The execution is cancelled when the given context is done.
+// See: AppDb.ExecMultiple
+func (appdb *AppDb) ExecMultipleContext(ctx context.Context, sqlCommands string) error {
+	defer appdb.killOnTimeout(ctx)()
 	commands := strings.Split(sqlCommands, ";")
 	for _, command := range commands {
 		trimmedCommand := strings.TrimSpace(command)
 		if trimmedCommand == "" {
 			continue
 		}
-		_, err := appdb.db.Exec(trimmedCommand)
+		_, err := appdb.executor().ExecContext(ctx, trimmedCommand)
 		if err != nil {
-			return err
+			return TimeoutError(ctx, err)
 		}
 	}
 	return nil
@@ -112,15 +303,23 @@ func (appdb *AppDb) ExecMultiple(sqlCommands string) error {
 // and returns the result if the execution is successful, otherwise it returns an error.
 // The result is a sql.Result instance that provides information about the number of affected rows and the last inserted ID.
 func (appdb *AppDb) PrepareExec(sqlCommand string, args ...any) (sql.Result, error) {
-	stmt, err := appdb.db.Prepare(sqlCommand)
+	return appdb.PrepareExecContext(context.Background(), sqlCommand, args...)
+}
+
+// PrepareExecContext prepares a SQL statement and executes it with the given data on the database connection.
+// The preparation and execution are cancelled when the given context is done.
+// See: AppDb.PrepareExec
+func (appdb *AppDb) PrepareExecContext(ctx context.Context, sqlCommand string, args ...any) (sql.Result, error) {
+	defer appdb.killOnTimeout(ctx)()
+	stmt, err := appdb.executor().PrepareContext(ctx, sqlCommand)
 	if err != nil {
-		return nil, err
+		return nil, TimeoutError(ctx, err)
 	}
 	defer stmt.Close()
 
-	result, err := stmt.Exec(args...)
+	result, err := stmt.ExecContext(ctx, args...)
 	if err != nil {
-		return nil, err
+		return nil, TimeoutError(ctx, err)
 	}
 	return result, nil
 }
@@ -130,7 +329,14 @@ func (appdb *AppDb) PrepareExec(sqlCommand string, args ...any) (sql.Result, err
 // returns a nil *sql.Row and a nil error. Otherwise it returns a *sql.Row
 // instance that can be used to retrieve the columns of the row, and a nil error.
 func (appdb *AppDb) QueryRow(sqlCommand string, args ...any) (*sql.Row, error) {
-	return appdb.db.QueryRow(sqlCommand, args...), nil
+	return appdb.QueryRowContext(context.Background(), sqlCommand, args...)
+}
+
+// QueryRowContext executes a SQL query with the given data on the database connection
+// and returns the first row of the result set. The query is cancelled when the given context is done.
+// See: AppDb.QueryRow
+func (appdb *AppDb) QueryRowContext(ctx context.Context, sqlCommand string, args ...any) (*sql.Row, error) {
+	return appdb.executor().QueryRowContext(ctx, sqlCommand, args...), nil
 }
 
 // Query executes a SQL query with the given data on the database connection
@@ -139,7 +345,21 @@ func (appdb *AppDb) QueryRow(sqlCommand string, args ...any) (*sql.Row, error) {
 // can be used to retrieve the columns and rows of the result set, and a nil
 // error.
 func (appdb *AppDb) Query(sqlCommand string, args ...any) (*sql.Rows, error) {
-	return appdb.db.Query(sqlCommand, args...)
+	return appdb.QueryContext(context.Background(), sqlCommand, args...)
+}
+
+// QueryContext executes a SQL query with the given data on the database connection and returns the result set.
+// The query and the reading of the result set are cancelled when the given context is done.
+// If KillQueryOnTimeout is set, the query is killed on the server when the context is cancelled by a timeout
+// before the context is done, so the context must be cancelled after the result set has been read.
+// See: AppDb.Query
+func (appdb *AppDb) QueryContext(ctx context.Context, sqlCommand string, args ...any) (*sql.Rows, error) {
+	appdb.killOnTimeout(ctx)
+	rows, err := appdb.executor().QueryContext(ctx, sqlCommand, args...)
+	if err != nil {
+		return nil, TimeoutError(ctx, err)
+	}
+	return rows, nil
 }
 
 // GetScalar executes a SQL query with the given data on the database connection
@@ -148,11 +368,19 @@ func (appdb *AppDb) Query(sqlCommand string, args ...any) (*sql.Rows, error) {
 // If the query returns multiple rows or columns, it only returns the first column of the first row.
 // If the query returns an error, it returns a nil value and the error.
 func (appdb *AppDb) GetScalar(sqlCommand string, args ...any) (any, error) {
+	return appdb.GetScalarContext(context.Background(), sqlCommand, args...)
+}
+
+// GetScalarContext executes a SQL query with the given data on the database connection and returns
+// the value of the first column of the first row. The query is cancelled when the given context is done.
+// See: AppDb.GetScalar
+func (appdb *AppDb) GetScalarContext(ctx context.Context, sqlCommand string, args ...any) (any, error) {
+	defer appdb.killOnTimeout(ctx)()
 	var value any
-	res := appdb.db.QueryRow(sqlCommand, args...)
+	res := appdb.executor().QueryRowContext(ctx, sqlCommand, args...)
 	err := res.Scan(&value)
 	if err != nil {
-		return nil, err
+		return nil, TimeoutError(ctx, err)
 	}
 	return value, nil
 }
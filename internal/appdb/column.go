@@ -0,0 +1,84 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// Numeric database type names without size and sign, for example "INT" for "INT4" or "UNSIGNED INT".
+var numericTypes = map[string]bool{
+	"INT":       true,
+	"INTEGER":   true,
+	"TINYINT":   true,
+	"SMALLINT":  true,
+	"MEDIUMINT": true,
+	"BIGINT":    true,
+	"UINT":      true,
+	"DECIMAL":   true,
+	"NUMERIC":   true,
+	"FLOAT":     true,
+	"DOUBLE":    true,
+	"REAL":      true,
+}
+
+// Column describes a column in the result set of the database query.
+type Column struct {
+	// Name of the column
+	Name string
+	// Database type name of the column as reported by the driver, for example "INT", "VARCHAR", "Nullable(Int32)".
+	// Empty if the driver does not report the type.
+	DatabaseType string
+	// True if the column may contain NULL values or the driver does not report it.
+	Nullable bool
+	// Precision of the decimal column, 0 if the driver does not report it.
+	Precision int64
+	// Scale of the decimal column.
+	Scale int64
+}
+
+// NewColumn creates a Column from the column type of the result set.
+func NewColumn(columnType *sql.ColumnType) Column {
+	column := Column{
+		Name:         columnType.Name(),
+		DatabaseType: columnType.DatabaseTypeName(),
+		Nullable:     true,
+	}
+	if nullable, ok := columnType.Nullable(); ok {
+		column.Nullable = nullable
+	}
+	if precision, scale, ok := columnType.DecimalSize(); ok {
+		column.Precision = precision
+		column.Scale = scale
+	}
+	return column
+}
+
+// BaseType returns the database type name in upper case without the Nullable wrapper,
+// the UNSIGNED modifier, the size in parentheses and the trailing size digits.
+// For example "Nullable(UInt64)" becomes "UINT", "DECIMAL(10,2)" becomes "DECIMAL", "INT8" becomes "INT".
+func (column Column) BaseType() string {
+	typeName := strings.ToUpper(strings.TrimSpace(column.DatabaseType))
+	if strings.HasPrefix(typeName, "NULLABLE(") && strings.HasSuffix(typeName, ")") {
+		typeName = typeName[len("NULLABLE(") : len(typeName)-1]
+	}
+	typeName = strings.TrimPrefix(typeName, "UNSIGNED ")
+	if i := strings.Index(typeName, "("); i >= 0 {
+		typeName = typeName[:i]
+	}
+	return strings.TrimSpace(strings.TrimRight(typeName, "0123456789"))
+}
+
+// IsUnsigned returns true if the column has an unsigned integer database type,
+// for example "UNSIGNED BIGINT" in MySQL or "UInt64" in ClickHouse.
+func (column Column) IsUnsigned() bool {
+	return strings.Contains(strings.ToUpper(column.DatabaseType), "UNSIGNED") || column.BaseType() == "UINT"
+}
+
+// IsNumeric returns true if the column has a numeric database type.
+// Some drivers return the values of numeric columns as byte slices, so the type is used to format them as numbers.
+func (column Column) IsNumeric() bool {
+	return numericTypes[column.BaseType()]
+}
@@ -0,0 +1,21 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestColumnIsNumeric verifies that the numeric database types of MySQL, PostgreSQL and ClickHouse
+// are recognized regardless of size, sign and the Nullable wrapper.
+func TestColumnIsNumeric(t *testing.T) {
+	for _, typeName := range []string{"INT", "UNSIGNED BIGINT", "DECIMAL", "INT8", "FLOAT8", "NUMERIC", "Nullable(UInt64)", "Decimal(10, 2)", "Float64"} {
+		assert.True(t, Column{DatabaseType: typeName}.IsNumeric(), typeName)
+	}
+	for _, typeName := range []string{"", "VARCHAR", "INTERVAL", "DATETIME", "Nullable(String)"} {
+		assert.False(t, Column{DatabaseType: typeName}.IsNumeric(), typeName)
+	}
+}
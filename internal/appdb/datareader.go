@@ -4,12 +4,62 @@
 package appdb
 
 import (
+	"context"
 	"copysqldatatool/internal/appevent"
 	"database/sql"
 	"fmt"
+	"reflect"
+	"strings"
 	"time"
 )
 
+// ReaderState represents the position of a DataReader in the source data.
+// It is used to save the reading progress and to resume reading from the saved position.
+type ReaderState struct {
+	// Query type the state was saved for
+	QueryType string `json:"query_type"`
+	// Last read id for query type "orderbyid"
+	LastId int64 `json:"last_id"`
+	// Last id of the partition for query type "orderbyid", 0 if the reader is not a partition
+	MaxId int64 `json:"max_id,omitempty"`
+	// Last read values of the key columns formatted as SQL literals for query type "orderbyid" with key columns
+	LastKey map[string]string `json:"last_key,omitempty"`
+	// Offset of the next row to read for query type "limitoffset"
+	Offset int64 `json:"offset"`
+	// Start value of the current range for query type "between"
+	BetweenStart string `json:"between_start"`
+	// End value and step of the range for query type "between"
+	BetweenEnd  string `json:"between_end,omitempty"`
+	BetweenStep string `json:"between_step,omitempty"`
+	// Number of rows already read from the query built for the saved position
+	Skip int64 `json:"skip"`
+}
+
+// IsEmpty returns true if the state does not contain any position, for example if it has not been saved.
+func (state ReaderState) IsEmpty() bool {
+	return reflect.DeepEqual(state, ReaderState{})
+}
+
+// String returns the position of the state in a readable form for the log,
+// for example "last id 100" for query type "orderbyid" or "offset 500" for "limitoffset".
+func (state ReaderState) String() string {
+	switch state.QueryType {
+	case QUERY_TYPE_ORDERBYID:
+		if len(state.LastKey) > 0 {
+			return fmt.Sprint("last key ", state.LastKey)
+		}
+		return fmt.Sprint("last id ", state.LastId)
+	case QUERY_TYPE_LIMIT_OFFSET:
+		return fmt.Sprint("offset ", state.Offset)
+	case QUERY_TYPE_BETWEEN:
+		return fmt.Sprint("range start ", state.BetweenStart, " rows read from range ", state.Skip)
+	case QUERY_TYPE_UNDEFINED:
+		return "start"
+	default:
+		return fmt.Sprint("rows read ", state.Skip)
+	}
+}
+
 // DataReader represents a database query reader with configurable parameters for executing and managing database queries.
 // It supports features like query pagination, execution time limits, and dynamic query parameter management.
 type DataReader struct {
@@ -24,6 +74,14 @@ type DataReader struct {
 	ResetConnection bool
 	// Initial Id for query type "orderbyid"
 	InitialId int64
+	// Last id for query type "orderbyid". If set, the reading stops at the first row with a greater id.
+	// It is used to read a partition of the ids, see: DataReader.Split
+	MaxId int64
+	// Names of the key columns for query type "orderbyid" in the order of the ORDER BY clause.
+	// The last values of the key columns replace the {{key.<name>}} and {{key}} placeholders.
+	KeyColumns []string
+	// Initial values of the key columns formatted as SQL literals by column name for query type "orderbyid"
+	InitialKey map[string]string
 	// Limit for query type "limitoffset"
 	Limit int64
 	// Initial Offset for query type "limitoffset"
@@ -39,16 +97,38 @@ type DataReader struct {
 	// Step between initial and final values in BETWEEN condition  for query type "between"
 	// Number or date string in format of range type '2h30m15s'
 	BetweenStep string
+	// Column of the range for query type "between". If set, the range values that are not set
+	// are calculated from the minimum and maximum values of the column before the first query
+	BetweenColumn string
+	// Target number of rows in one range for query type "between" used to calculate the step
+	BetweenChunkRows int64
+	// Source of the number of rows used to calculate the step: "count" (default) or "estimate"
+	BetweenRowCount string
+	// Max time of the query and of the wait for each next row. If 0, the time is not limited.
+	// A query that exceeds it fails with an error that wraps ErrTimeout.
+	QueryTimeout time.Duration
+	// Retry policy of the queries failed with a transient error. The failed query is executed again
+	// on a reopened connection from the position of the last read row.
+	Retry RetryPolicy
 	// Event fired when the query is changed
 	OnQueryChanged appevent.AppEvent
 	queryProcessor QueryProcessorInterface
 	columns        []string
+	columnTypes    []Column
 	rows           *sql.Rows
 	valuePtrs      []any
 	values         []any
 	startTime      time.Time
 	prevQuery      string
 	lastQuery      string
+	// Number of rows read from the current query
+	queryRows int64
+	// Number of rows to skip in the next query when resuming from a saved state
+	skip int64
+	// Indexes of the key columns in the result set
+	keyIndexes []int
+	// Context of the current query with the query timeout
+	queryCtx *timeoutContext
 }
 
 // Open opens the database connection for the underlying AppDb instance.
@@ -65,8 +145,10 @@ func (dataReader *DataReader) Close() {
 	dataReader.prevQuery = ""
 	dataReader.lastQuery = ""
 	dataReader.columns = nil
+	dataReader.columnTypes = nil
 	dataReader.valuePtrs = nil
 	dataReader.values = nil
+	dataReader.keyIndexes = nil
 	dataReader.AppDb.Close()
 }
 
@@ -83,6 +165,10 @@ func (dataReader *DataReader) closeRows() {
 		dataReader.rows.Close()
 		dataReader.rows = nil
 	}
+	if dataReader.queryCtx != nil {
+		dataReader.queryCtx.close()
+		dataReader.queryCtx = nil
+	}
 }
 
 // reopenAppDbByExecutionTime checks if the connection should be reset by the specified
@@ -121,12 +207,24 @@ func (dataReader *DataReader) reopenAppDb() *DataReader {
 // query executes the prepared SQL query and sets the rows and columns of the DataReader instance.
 // It also handles connection reopening if the query execution time has exceeded the allowed
 // ExecutionTime and handles the case when the AppDb connection is not open.
+// The query and the reading of its rows are cancelled when the given context is done.
 // It returns an error if the query execution fails.
-func (dataReader *DataReader) query() error {
+func (dataReader *DataReader) query(ctx context.Context) error {
 	if dataReader.queryProcessor == nil {
 		dataReader.initQueryProcessor()
+		if err := dataReader.checkKeyPlaceholders(); err != nil {
+			dataReader.queryProcessor = nil
+			return err
+		}
+		if err := dataReader.initRange(); err != nil {
+			dataReader.queryProcessor = nil
+			return err
+		}
 	}
 	dataReader.queryProcessor.SetValue("id", dataReader.getLastId())
+	if len(dataReader.KeyColumns) > 0 {
+		dataReader.queryProcessor.SetValue("key", dataReader.getLastKey())
+	}
 	query := dataReader.queryProcessor.ProcessQuery()
 	dataReader.prevQuery = dataReader.lastQuery
 	dataReader.lastQuery = query
@@ -137,6 +235,12 @@ func (dataReader *DataReader) query() error {
 	// Protection against error :Error 3024 (HY000): Query execution was interrupted, maximum statement execution time exceeded
 	dataReader.reopenAppDbByExecutionTime()
 
+	return dataReader.execute(ctx, query)
+}
+
+// execute executes the given query and sets the rows and columns of the DataReader instance.
+// The rows already read from the query before the state was saved or before the query failed are skipped.
+func (dataReader *DataReader) execute(ctx context.Context, query string) error {
 	if !dataReader.AppDb.IsOpen() {
 		err := dataReader.AppDb.Open()
 		if err != nil {
@@ -146,23 +250,63 @@ func (dataReader *DataReader) query() error {
 	}
 
 	dataReader.closeRows()
+	dataReader.queryRows = 0
 
-	rows, err := dataReader.AppDb.Query(query, dataReader.Args...)
+	dataReader.queryCtx = newTimeoutContext(ctx, dataReader.QueryTimeout)
+	dataReader.queryCtx.start()
+	rows, err := dataReader.AppDb.QueryContext(dataReader.queryCtx.ctx, query, dataReader.Args...)
 	if err != nil {
+		dataReader.closeRows()
 		return err
 	}
 
+	// Skip the rows that were already read before the state was saved.
+	// If the query fails, the rows are skipped again when it is retried
+	var skipped int64
+	for skipped < dataReader.skip && rows.Next() {
+		skipped++
+	}
+	dataReader.queryCtx.stop()
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		err = TimeoutError(dataReader.queryCtx.ctx, err)
+		dataReader.closeRows()
+		return err
+	}
+	dataReader.skip = 0
+	dataReader.queryRows = skipped
+
 	columns, err := rows.Columns()
 	if err != nil {
 		return err
 	}
 
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+
+	keyIndexes, err := dataReader.getKeyIndexes(columns)
+	if err != nil {
+		rows.Close()
+		return err
+	}
+	dataReader.keyIndexes = keyIndexes
+
 	dataReader.columns = columns
+	dataReader.columnTypes = make([]Column, len(columnTypes))
+	for i, columnType := range columnTypes {
+		dataReader.columnTypes[i] = NewColumn(columnType)
+	}
 	dataReader.rows = rows
-	dataReader.valuePtrs = make([]any, len(columns))
-	dataReader.values = make([]any, len(columns))
-	for i := range dataReader.values {
-		dataReader.valuePtrs[i] = &dataReader.values[i]
+	// The values of the last read row are kept until the next row is scanned,
+	// as they are the position of the query type "orderbyid" if the query is executed again
+	if len(dataReader.values) != len(columns) {
+		dataReader.valuePtrs = make([]any, len(columns))
+		dataReader.values = make([]any, len(columns))
+		for i := range dataReader.values {
+			dataReader.valuePtrs[i] = &dataReader.values[i]
+		}
 	}
 
 	return nil
@@ -173,7 +317,7 @@ func (dataReader *DataReader) query() error {
 // processor, including the initial ID, limit, and offset, and assigns the query processor
 // to the DataReader's queryProcessor field.
 func (dataReader *DataReader) initQueryProcessor() {
-	queryProcessorFactory := QueryProcessorFactory{}
+	queryProcessorFactory := QueryProcessorFactory{Dialect: dataReader.AppDb.GetDialect()}
 	values := make(map[string]any)
 	values["id"] = dataReader.InitialId
 	values["limit"] = dataReader.Limit
@@ -182,18 +326,139 @@ func (dataReader *DataReader) initQueryProcessor() {
 	values["start"] = dataReader.BetweenStart
 	values["end"] = dataReader.BetweenEnd
 	values["step"] = dataReader.BetweenStep
+	values["column"] = dataReader.BetweenColumn
+	values["chunk_rows"] = dataReader.BetweenChunkRows
+	values["row_count"] = dataReader.BetweenRowCount
+	values["key_columns"] = dataReader.KeyColumns
+	values["key"] = dataReader.InitialKey
 	dataReader.queryProcessor = queryProcessorFactory.CreateQueryProcessor(dataReader.QueryType, dataReader.Query, values)
 }
 
+// initRange calculates the range of the query type "between" from the range column
+// and sets the calculated values as the initial range values.
+// See: QueryProcessorBetween.InitRange
+func (dataReader *DataReader) initRange() error {
+	processor, ok := dataReader.queryProcessor.(*QueryProcessorBetween)
+	if !ok || dataReader.BetweenColumn == "" {
+		return nil
+	}
+	if !dataReader.AppDb.IsOpen() {
+		err := dataReader.AppDb.Open()
+		if err != nil {
+			return err
+		}
+		defer dataReader.AppDb.Close()
+	}
+	err := processor.InitRange(dataReader.AppDb)
+	if err != nil {
+		return fmt.Errorf("error calculating range of column %s: %w", dataReader.BetweenColumn, err)
+	}
+	dataReader.BetweenStart = processor.Start
+	dataReader.BetweenEnd = processor.End
+	dataReader.BetweenStep = processor.Step
+	return nil
+}
+
+// checkKeyPlaceholders returns an error if the query of type "orderbyid" contains the {{key.<name>}}
+// placeholders while the initial values of the key columns are not set, as the first query cannot be built.
+// The {{key}} placeholder does not need the initial values, as it is replaced by the condition that is always true.
+func (dataReader *DataReader) checkKeyPlaceholders() error {
+	processor, ok := dataReader.queryProcessor.(*QueryProcessorOrderByID)
+	if !ok || len(dataReader.KeyColumns) == 0 || processor.HasKey() {
+		return nil
+	}
+	for _, column := range dataReader.KeyColumns {
+		if strings.Contains(dataReader.Query, fmt.Sprintf(KEY_COLUMN_PLACEHOLDER, column)) {
+			return fmt.Errorf("initial value of the key column %s is not set", column)
+		}
+	}
+	return nil
+}
+
+// getKeyIndexes returns the indexes of the key columns in the given columns of the result set.
+// It returns an error if a key column is not found, as the position of the reader cannot be tracked without it.
+func (dataReader *DataReader) getKeyIndexes(columns []string) ([]int, error) {
+	if dataReader.QueryType != QUERY_TYPE_ORDERBYID || len(dataReader.KeyColumns) == 0 {
+		return nil, nil
+	}
+	indexes := make([]int, len(dataReader.KeyColumns))
+	for i, keyColumn := range dataReader.KeyColumns {
+		indexes[i] = -1
+		for j, column := range columns {
+			if strings.EqualFold(column, keyColumn) {
+				indexes[i] = j
+				break
+			}
+		}
+		if indexes[i] < 0 {
+			return nil, fmt.Errorf("key column %s is not in the result set", keyColumn)
+		}
+	}
+	return indexes, nil
+}
+
 // getLastId returns the last ID in the result set of the database query.
+// The ID is the value of the first key column if the key columns are set, otherwise the first column.
 // If the query returned no rows, it returns the InitialId field.
 func (dataReader *DataReader) getLastId() int64 {
-	// If the values slice is empty or the first element is nil (no rows returned), return the initial ID
-	if len(dataReader.values) == 0 || dataReader.values[0] == nil {
+	index := 0
+	if len(dataReader.keyIndexes) > 0 {
+		index = dataReader.keyIndexes[0]
+	}
+	// If the values slice is empty or the element is nil (no rows returned), return the initial ID
+	if len(dataReader.values) <= index || dataReader.values[index] == nil {
 		return dataReader.InitialId
 	}
 
-	return dataReader.AnyToInt64(dataReader.values[0])
+	return dataReader.AnyToInt64(dataReader.values[index])
+}
+
+// getLastKey returns the values of the key columns in the last read row formatted as SQL literals
+// according to the dialect of the source database. The values of numeric columns are formatted as numbers,
+// even if the driver returns them as byte slices, so the key comparison is not done on strings.
+// If no row has been read from the current query, it returns the key values of the current query.
+func (dataReader *DataReader) getLastKey() map[string]string {
+	if len(dataReader.keyIndexes) == 0 || dataReader.values[dataReader.keyIndexes[0]] == nil {
+		return dataReader.getQueryKey()
+	}
+	formatter := Formatter{Dialect: dataReader.AppDb.GetDialect()}
+	key := make(map[string]string, len(dataReader.keyIndexes))
+	for i, index := range dataReader.keyIndexes {
+		value := dataReader.values[index]
+		if bytes, ok := value.([]byte); ok && dataReader.columnTypes[index].IsNumeric() && isNumber(bytes) {
+			key[dataReader.KeyColumns[i]] = string(bytes)
+			continue
+		}
+		key[dataReader.KeyColumns[i]] = formatter.FormatValue(value)
+	}
+	return key
+}
+
+// getQueryKey returns the key values of the current query or the InitialKey field if no query has been built.
+func (dataReader *DataReader) getQueryKey() map[string]string {
+	if dataReader.queryProcessor == nil {
+		return dataReader.InitialKey
+	}
+	key, _ := dataReader.queryProcessor.GetState()["key"].(map[string]string)
+	return key
+}
+
+// isNumber returns true if the given bytes are a decimal number, optionally signed and with a fractional part.
+func isNumber(value []byte) bool {
+	digits := 0
+	point := false
+	for i, b := range value {
+		switch {
+		case b >= '0' && b <= '9':
+			digits++
+		case b == '-' && i == 0:
+		case b == '.' && digits > 0 && !point:
+			point = true
+		default:
+			return false
+		}
+	}
+	return digits > 0
 }
 
 // Columns returns a slice of strings containing the names of the columns
@@ -202,16 +467,19 @@ func (dataReader *DataReader) Columns() []string {
 	return dataReader.columns
 }
 
+// ColumnTypes returns the names and the database types of the columns
+// in the result set of the database query.
+func (dataReader *DataReader) ColumnTypes() []Column {
+	return dataReader.columnTypes
+}
+
 // WrappedColumns returns a slice of strings containing the names of the columns
-// in the result set of the database query, each wrapped in backticks.
-// This is useful for formatting column names for SQL queries that require
-// column names to be enclosed in backticks.
+// in the result set of the database query, each quoted according to the dialect
+// of the source database (backticks for MySQL, double quotes for PostgreSQL).
+// This is useful for formatting column names for SQL queries.
 func (dataReader *DataReader) WrappedColumns() []string {
-	cols := make([]string, len(dataReader.columns))
-	for i, col := range dataReader.columns {
-		cols[i] = fmt.Sprintf("`%s`", col)
-	}
-	return cols
+	formatter := Formatter{Dialect: dataReader.AppDb.GetDialect()}
+	return formatter.QuoteIdentifiers(dataReader.columns)
 }
 
 // Next reads the next row from the database query. It returns true if there is a next row,
@@ -219,31 +487,96 @@ func (dataReader *DataReader) WrappedColumns() []string {
 // If the query is exhausted, it closes the database reader and returns false and nil.
 // It also handles the case where the query type has changed by re-executing the query
 // and checking if there is a next row. It is idempotent and can be called multiple times.
-func (dataReader *DataReader) Next() (bool, error) {
+// The reading is cancelled when the given context is done, in this case the error of the context is returned.
+// A query failed with a transient error is retried according to the Retry policy, see: DataReader.rerun
+func (dataReader *DataReader) Next(ctx context.Context) (bool, error) {
+	var hasNext bool
+	err := dataReader.Retry.Do(ctx, dataReader.AppDb.GetDialect(), func(attempt int) error {
+		var err error
+		if attempt > 1 {
+			if err = dataReader.rerun(ctx); err != nil {
+				return err
+			}
+		}
+		hasNext, err = dataReader.next(ctx)
+		return err
+	})
+	return hasNext, err
+}
+
+// rerun reopens the connection and executes the current query again from the position of the last read row
+// after the query has failed. The query of type "orderbyid" is built again from the last read key,
+// the other queries are executed again and the rows already read from them are skipped.
+// If no query has been executed yet, the first query is executed by the next call of next.
+func (dataReader *DataReader) rerun(ctx context.Context) error {
+	dataReader.closeRows()
+	if err := dataReader.AppDb.Reopen(); err != nil {
+		return err
+	}
+	if dataReader.lastQuery == "" || dataReader.queryProcessor == nil {
+		return nil
+	}
+	if dataReader.queryProcessor.GetType() == QUERY_TYPE_ORDERBYID {
+		return dataReader.query(ctx)
+	}
+	dataReader.skip += dataReader.queryRows
+	return dataReader.execute(ctx, dataReader.lastQuery)
+}
+
+// next reads the next row from the database query, see: DataReader.Next
+func (dataReader *DataReader) next(ctx context.Context) (bool, error) {
 	if dataReader.rows == nil {
-		err := dataReader.query()
+		err := dataReader.query(ctx)
 		if err != nil {
 			return false, err
 		}
 	}
-	hasNext := dataReader.rows.Next()
+	hasNext := dataReader.nextRow()
 	if !hasNext {
-		err := dataReader.query()
+		// The rows of a failed or cancelled query must not be taken for the end of the query
+		if err := dataReader.rowsErr(); err != nil {
+			return false, err
+		}
+		err := dataReader.query(ctx)
 		if err != nil {
 			return false, err
 		}
 		// Protection against infinite loop if the query does not match the specified query type
 		if dataReader.lastQuery != dataReader.prevQuery {
-			hasNext = dataReader.rows.Next()
+			hasNext = dataReader.nextRow()
+		}
+		if err := dataReader.rowsErr(); !hasNext && err != nil {
+			return false, err
+		}
+	}
+	if hasNext && dataReader.MaxId > 0 && dataReader.QueryType == QUERY_TYPE_ORDERBYID {
+		// The rows after the last id of the partition are read by the next partition
+		if _, err := dataReader.Scan(); err != nil {
+			return false, err
 		}
+		hasNext = dataReader.getLastId() <= dataReader.MaxId
 	}
 	if !hasNext {
 		dataReader.Close()
 		return false, nil
 	}
+	dataReader.queryRows++
 	return true, nil
 }
 
+// nextRow moves to the next row of the current query, the wait for the row is limited by the query timeout.
+func (dataReader *DataReader) nextRow() bool {
+	dataReader.queryCtx.start()
+	defer dataReader.queryCtx.stop()
+	return dataReader.rows.Next()
+}
+
+// rowsErr returns the error of the current query encountered while reading its rows.
+// The error wraps ErrTimeout if the query has been cancelled by the query timeout.
+func (dataReader *DataReader) rowsErr() error {
+	return TimeoutError(dataReader.queryCtx.ctx, dataReader.rows.Err())
+}
+
 // Scan reads the next row from the database query and returns a slice of any values.
 // It returns an error if the scan operation fails.
 // It is idempotent and can be called multiple times.
@@ -254,6 +587,77 @@ func (dataReader *DataReader) Scan() ([]any, error) {
 	return dataReader.values, nil
 }
 
+// GetState returns the position of the DataReader after the last read row.
+// The returned state can be saved and passed to Resume to continue reading from the same position.
+// For query type "orderbyid" the position is the last read id, for "limitoffset" it is the offset
+// of the next row, for "between" and "simple" it is the start of the current query and the number
+// of rows already read from it.
+func (dataReader *DataReader) GetState() ReaderState {
+	state := ReaderState{
+		QueryType:    dataReader.QueryType,
+		LastId:       dataReader.InitialId,
+		MaxId:        dataReader.MaxId,
+		LastKey:      dataReader.InitialKey,
+		Offset:       dataReader.InitialOffset,
+		BetweenStart: dataReader.BetweenStart,
+		BetweenEnd:   dataReader.BetweenEnd,
+		BetweenStep:  dataReader.BetweenStep,
+	}
+	if dataReader.queryProcessor == nil {
+		return state
+	}
+	values := dataReader.queryProcessor.GetState()
+	switch dataReader.queryProcessor.GetType() {
+	case QUERY_TYPE_ORDERBYID:
+		state.LastId = dataReader.getLastId()
+		state.MaxId = dataReader.MaxId
+		if len(dataReader.KeyColumns) > 0 {
+			state.LastKey = dataReader.getLastKey()
+		}
+	case QUERY_TYPE_LIMIT_OFFSET:
+		state.Offset = dataReader.AnyToInt64(values["offset"]) + dataReader.queryRows
+	case QUERY_TYPE_BETWEEN:
+		state.BetweenStart = values["start"].(string)
+		state.Skip = dataReader.queryRows
+		state.BetweenEnd = values["end"].(string)
+		state.BetweenStep = values["step"].(string)
+	default:
+		state.Skip = dataReader.queryRows
+	}
+	return state
+}
+
+// Resume sets the initial position of the DataReader to the given saved state.
+// The first query starts from the saved position and skips the rows that were already read.
+// It returns an error if the state was saved for another query type.
+func (dataReader *DataReader) Resume(state ReaderState) error {
+	if state.QueryType != dataReader.QueryType {
+		return fmt.Errorf("saved state query type %q does not match query type %q", state.QueryType, dataReader.QueryType)
+	}
+	switch dataReader.QueryType {
+	case QUERY_TYPE_ORDERBYID:
+		dataReader.InitialId = state.LastId
+		dataReader.MaxId = state.MaxId
+		if state.LastKey != nil {
+			dataReader.InitialKey = state.LastKey
+		}
+	case QUERY_TYPE_LIMIT_OFFSET:
+		dataReader.InitialOffset = state.Offset
+	case QUERY_TYPE_BETWEEN:
+		if state.BetweenStart != "" {
+			dataReader.BetweenStart = state.BetweenStart
+		}
+		if state.BetweenEnd != "" {
+			dataReader.BetweenEnd = state.BetweenEnd
+		}
+		if state.BetweenStep != "" {
+			dataReader.BetweenStep = state.BetweenStep
+		}
+	}
+	dataReader.skip = state.Skip
+	return nil
+}
+
 // AnyToInt64 converts a value of any type to an int64, returning 0 if the value
 // cannot be converted. It supports conversion of the following types to int64:
 // int64, int, int32, int16, int8, uint64, uint, uint32, uint16, uint8, float64,
@@ -4,7 +4,9 @@
 package appdb
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -76,7 +78,7 @@ func insertTestRows(t *testing.T, dr *DataReader, rowsCount int) *DataReader {
 func readTestRows(t *testing.T, dr *DataReader) int {
 	counter := 0
 	for {
-		next, err := dr.Next()
+		next, err := dr.Next(context.Background())
 		if err != nil {
 			t.Error(err)
 			break
@@ -224,3 +226,103 @@ func TestDatReaderResetConnection(t *testing.T) {
 	counter := readTestRows(t, dr)
 	assert.Equal(t, counter, 10)
 }
+
+// TestDataReaderResume verifies that Resume sets the initial position of the DataReader
+// from the saved state and rejects a state saved for another query type.
+func TestDataReaderResume(t *testing.T) {
+	dr := DataReader{QueryType: QUERY_TYPE_ORDERBYID, InitialId: 1}
+	err := dr.Resume(ReaderState{QueryType: QUERY_TYPE_ORDERBYID, LastId: 100})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), dr.GetState().LastId)
+	err = dr.Resume(ReaderState{QueryType: QUERY_TYPE_LIMIT_OFFSET, Offset: 100})
+	assert.NotNil(t, err)
+}
+
+// prepareSqliteDrKey returns a DataReader instance configured to read the rows of a SQLite table
+// with the composite key (tenant_id, name) in pages of 3 rows using the given query.
+// The table contains 10 rows, the key columns are not the first selected columns.
+func prepareSqliteDrKey(t *testing.T, query string) *DataReader {
+	dsn := filepath.Join(t.TempDir(), "key.db")
+	db := AppDb{Driver: DRIVER_SQLITE, Dsn: dsn}
+	assert.NoError(t, db.Open())
+	defer db.Close()
+	_, err := db.Exec("CREATE TABLE test (val INTEGER, name TEXT, tenant_id INTEGER, PRIMARY KEY (tenant_id, name))")
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err = db.Exec(fmt.Sprintf("INSERT INTO test VALUES (%d, 'n''%d', %d)", i, i%4, i%3))
+		assert.NoError(t, err)
+	}
+	dr := DataReader{
+		AppDb:      &AppDb{Driver: DRIVER_SQLITE, Dsn: dsn},
+		Query:      query,
+		QueryType:  QUERY_TYPE_ORDERBYID,
+		KeyColumns: []string{"tenant_id", "name"},
+	}
+	assert.NoError(t, dr.Open())
+	return &dr
+}
+
+// TestDataReaderKeyColumns verifies that the DataReader reads all rows once by the composite key
+// using the {{key}} placeholder, and that the saved state contains the last key values.
+func TestDataReaderKeyColumns(t *testing.T) {
+	dr := prepareSqliteDrKey(t, "SELECT * FROM test WHERE {{key}} ORDER BY tenant_id, name LIMIT 3")
+	defer dr.Close()
+	vals := []int64{}
+	for {
+		next, err := dr.Next(context.Background())
+		assert.NoError(t, err)
+		if !next {
+			break
+		}
+		row, err := dr.Scan()
+		assert.NoError(t, err)
+		vals = append(vals, row[0].(int64))
+		if len(vals) == 4 {
+			assert.Equal(t, map[string]string{"tenant_id": "0", "name": "'n''3'"}, dr.GetState().LastKey)
+			assert.Contains(t, dr.GetLastQuery(), `("tenant_id" > 0 OR ("tenant_id" = 0 AND "name" > 'n''2'))`)
+		}
+	}
+	assert.Equal(t, []int64{0, 9, 6, 3, 4, 1, 7, 8, 5, 2}, vals)
+}
+
+// TestDataReaderKeyColumnPlaceholders verifies that the {{key.<name>}} placeholders are replaced
+// with the initial key values and then with the last read key values, and that an error is returned
+// if the initial key values are not set or a key column is not in the result set.
+func TestDataReaderKeyColumnPlaceholders(t *testing.T) {
+	query := "SELECT * FROM test WHERE (tenant_id, name) > ({{key.tenant_id}}, {{key.name}}) ORDER BY tenant_id, name LIMIT 3"
+	dr := prepareSqliteDrKey(t, query)
+	defer dr.Close()
+	_, err := dr.Next(context.Background())
+	assert.ErrorContains(t, err, "initial value of the key column tenant_id is not set")
+
+	dr = prepareSqliteDrKey(t, query)
+	defer dr.Close()
+	assert.NoError(t, dr.Resume(ReaderState{QueryType: QUERY_TYPE_ORDERBYID, LastKey: map[string]string{"tenant_id": "1", "name": "'n''3'"}}))
+	counter := 0
+	for {
+		next, err := dr.Next(context.Background())
+		assert.NoError(t, err)
+		if !next {
+			break
+		}
+		_, err = dr.Scan()
+		assert.NoError(t, err)
+		counter++
+	}
+	assert.Equal(t, 3, counter)
+
+	dr = prepareSqliteDrKey(t, "SELECT val FROM test WHERE {{key}} ORDER BY tenant_id, name LIMIT 3")
+	defer dr.Close()
+	_, err = dr.Next(context.Background())
+	assert.ErrorContains(t, err, "key column tenant_id is not in the result set")
+}
+
+// TestReaderStateString verifies the readable position of the reader state for each query type.
+func TestReaderStateString(t *testing.T) {
+	assert.Equal(t, "start", ReaderState{}.String())
+	assert.Equal(t, "last id 10", ReaderState{QueryType: QUERY_TYPE_ORDERBYID, LastId: 10}.String())
+	assert.Equal(t, "last key map[id:10]", ReaderState{QueryType: QUERY_TYPE_ORDERBYID, LastKey: map[string]string{"id": "10"}}.String())
+	assert.Equal(t, "offset 20", ReaderState{QueryType: QUERY_TYPE_LIMIT_OFFSET, Offset: 20}.String())
+	assert.Equal(t, "range start 5 rows read from range 3", ReaderState{QueryType: QUERY_TYPE_BETWEEN, BetweenStart: "5", Skip: 3}.String())
+	assert.Equal(t, "rows read 7", ReaderState{QueryType: QUERY_TYPE_SIMPLE, Skip: 7}.String())
+}
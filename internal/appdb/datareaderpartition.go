@@ -0,0 +1,182 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Constants for partitioning.
+const (
+	// Id column of the query type "orderbyid" if the key columns are not set
+	PARTITION_ID_COLUMN = "id"
+)
+
+// Split splits the rows of the DataReader into the given number of partitions with disjoint ranges
+// and returns a new DataReader for each partition. Each partition reader opens its own database connection.
+// For query type "between" the range from BetweenStart to BetweenEnd (calculated from BetweenColumn if set)
+// is split on the step boundaries, so the partitions run the same queries as the DataReader would.
+// For query type "orderbyid" the ids after InitialId up to the maximum id of the id column are split
+// into equal ranges, each partition reader starts after its first id and stops at its MaxId.
+// The id column is the key column or "id" if the key columns are not set, it must be an integer column.
+// Partitions without rows are not returned, so fewer readers than requested may be returned.
+// It returns an error for other query types or composite key columns.
+func (dataReader *DataReader) Split(count int) ([]*DataReader, error) {
+	if count <= 1 {
+		return []*DataReader{dataReader.clone()}, nil
+	}
+	if !dataReader.AppDb.IsOpen() {
+		err := dataReader.AppDb.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer dataReader.AppDb.Close()
+	}
+	switch dataReader.QueryType {
+	case QUERY_TYPE_BETWEEN:
+		return dataReader.splitBetween(count)
+	case QUERY_TYPE_ORDERBYID:
+		return dataReader.splitOrderById(count)
+	default:
+		return nil, fmt.Errorf("query type %q cannot be split into partitions", dataReader.QueryType)
+	}
+}
+
+// splitBetween splits the range of the query type "between" into the given number of partitions.
+// The range is split into steps and each partition gets an equal number of steps.
+func (dataReader *DataReader) splitBetween(count int) ([]*DataReader, error) {
+	dataReader.initQueryProcessor()
+	defer func() { dataReader.queryProcessor = nil }()
+	if err := dataReader.initRange(); err != nil {
+		return nil, err
+	}
+	processor := dataReader.queryProcessor.(*QueryProcessorBetween)
+	var bound func(step int64) string
+	var steps int64
+	if processor.isNumericFields() {
+		start, _ := strconv.ParseInt(processor.Start, 10, 64)
+		end, _ := strconv.ParseInt(processor.End, 10, 64)
+		step, _ := strconv.ParseInt(processor.Step, 10, 64)
+		if step <= 0 {
+			return nil, fmt.Errorf("invalid range step: %s", processor.Step)
+		}
+		steps = (end - start + step - 1) / step
+		bound = func(i int64) string {
+			return strconv.FormatInt(min(start+i*step, end), 10)
+		}
+	} else {
+		start, errStart := time.Parse(DATE_TIME_LAYOUT, processor.Start)
+		end, errEnd := time.Parse(DATE_TIME_LAYOUT, processor.End)
+		step, errStep := time.ParseDuration(processor.Step)
+		if errStart != nil || errEnd != nil || errStep != nil || step <= 0 {
+			return nil, fmt.Errorf("invalid range: %s, %s, %s", processor.Start, processor.End, processor.Step)
+		}
+		steps = int64((end.Sub(start) + step - 1) / step)
+		bound = func(i int64) string {
+			value := start.Add(time.Duration(i) * step)
+			if value.After(end) {
+				value = end
+			}
+			return value.Format(DATE_TIME_LAYOUT)
+		}
+	}
+	steps = max(steps, 1)
+	readers := make([]*DataReader, 0, count)
+	for i := range int64(count) {
+		first, last := i*steps/int64(count), (i+1)*steps/int64(count)
+		if first == last {
+			continue
+		}
+		reader := dataReader.clone()
+		reader.BetweenColumn = ""
+		reader.BetweenStart = bound(first)
+		reader.BetweenEnd = bound(last)
+		reader.BetweenStep = processor.Step
+		readers = append(readers, reader)
+	}
+	return readers, nil
+}
+
+// splitOrderById splits the ids of the query type "orderbyid" into the given number of partitions.
+// The ids after InitialId up to the maximum id (or MaxId if set) are split into equal ranges.
+func (dataReader *DataReader) splitOrderById(count int) ([]*DataReader, error) {
+	column := PARTITION_ID_COLUMN
+	if len(dataReader.KeyColumns) > 1 {
+		return nil, fmt.Errorf("composite key columns cannot be split into partitions")
+	}
+	if len(dataReader.KeyColumns) == 1 {
+		column = dataReader.KeyColumns[0]
+	}
+	sqlHelper := SqlHelper{Sql: dataReader.Query}
+	table := sqlHelper.GetFromTableName()
+	if table == "" {
+		return nil, fmt.Errorf("table name not found in the query")
+	}
+	minValue, maxValue, err := GetColumnRange(dataReader.AppDb, table, column)
+	if err != nil {
+		return nil, err
+	}
+	if minValue == nil || maxValue == nil {
+		return []*DataReader{dataReader.clone()}, nil
+	}
+	minId, minIsInt := toRangeInt(minValue)
+	maxId, maxIsInt := toRangeInt(maxValue)
+	if !minIsInt || !maxIsInt {
+		return nil, fmt.Errorf("id column %s must be an integer column to be split into partitions", column)
+	}
+	first := max(dataReader.InitialId, minId-1)
+	last := maxId
+	if dataReader.MaxId > 0 {
+		last = min(last, dataReader.MaxId)
+	}
+	if last <= first {
+		return []*DataReader{dataReader.clone()}, nil
+	}
+	size := (last - first + int64(count) - 1) / int64(count)
+	readers := make([]*DataReader, 0, count)
+	for start := first; start < last; start += size {
+		reader := dataReader.clone()
+		reader.InitialId = start
+		reader.MaxId = min(start+size, last)
+		if len(dataReader.KeyColumns) == 1 {
+			reader.InitialKey = map[string]string{column: strconv.FormatInt(start, 10)}
+		}
+		readers = append(readers, reader)
+	}
+	return readers, nil
+}
+
+// clone returns a new DataReader with the same configuration and a new database connection.
+func (dataReader *DataReader) clone() *DataReader {
+	return &DataReader{
+		AppDb: &AppDb{
+			Driver:             dataReader.AppDb.Driver,
+			Dsn:                dataReader.AppDb.Dsn,
+			KillQueryOnTimeout: dataReader.AppDb.KillQueryOnTimeout,
+		},
+		Query:            dataReader.Query,
+		Args:             dataReader.Args,
+		QueryType:        dataReader.QueryType,
+		Params:           dataReader.Params,
+		ExecutionTime:    dataReader.ExecutionTime,
+		ResetConnection:  dataReader.ResetConnection,
+		InitialId:        dataReader.InitialId,
+		MaxId:            dataReader.MaxId,
+		KeyColumns:       dataReader.KeyColumns,
+		InitialKey:       dataReader.InitialKey,
+		Limit:            dataReader.Limit,
+		InitialOffset:    dataReader.InitialOffset,
+		MaxOffset:        dataReader.MaxOffset,
+		BetweenStart:     dataReader.BetweenStart,
+		BetweenEnd:       dataReader.BetweenEnd,
+		BetweenStep:      dataReader.BetweenStep,
+		BetweenColumn:    dataReader.BetweenColumn,
+		BetweenChunkRows: dataReader.BetweenChunkRows,
+		BetweenRowCount:  dataReader.BetweenRowCount,
+		QueryTimeout:     dataReader.QueryTimeout,
+		Retry:            dataReader.Retry,
+	}
+}
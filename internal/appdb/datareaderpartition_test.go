@@ -0,0 +1,101 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// prepareSqlitePartitionDb creates a SQLite database with the table test of 100 rows with ids from 11 to 110
+// and returns its DSN.
+func prepareSqlitePartitionDb(t *testing.T) string {
+	dsn := filepath.Join(t.TempDir(), "partition.db")
+	db := AppDb{Driver: DRIVER_SQLITE, Dsn: dsn}
+	assert.NoError(t, db.Open())
+	defer db.Close()
+	_, err := db.Exec("CREATE TABLE test (id INTEGER PRIMARY KEY, created_at TEXT)")
+	assert.NoError(t, err)
+	for i := 11; i <= 110; i++ {
+		_, err = db.Exec(fmt.Sprintf("INSERT INTO test VALUES (%d, '2025-01-01 %02d:%02d:00')", i, (i-11)/60, (i-11)%60))
+		assert.NoError(t, err)
+	}
+	return dsn
+}
+
+// readPartitionIds reads the ids of all rows of the given partition readers.
+func readPartitionIds(t *testing.T, readers []*DataReader) [][]int64 {
+	ids := make([][]int64, len(readers))
+	for i, reader := range readers {
+		assert.NoError(t, reader.Open())
+		for {
+			next, err := reader.Next(context.Background())
+			assert.NoError(t, err)
+			if !next {
+				break
+			}
+			row, err := reader.Scan()
+			assert.NoError(t, err)
+			ids[i] = append(ids[i], row[0].(int64))
+		}
+		reader.Close()
+	}
+	return ids
+}
+
+// TestDataReaderSplitOrderById verifies that the ids of the query type "orderbyid" are split
+// into disjoint partitions that read all rows once.
+func TestDataReaderSplitOrderById(t *testing.T) {
+	dr := DataReader{
+		AppDb:     &AppDb{Driver: DRIVER_SQLITE, Dsn: prepareSqlitePartitionDb(t)},
+		Query:     "SELECT * FROM test WHERE id > {{id}} ORDER BY id LIMIT 7",
+		QueryType: QUERY_TYPE_ORDERBYID,
+		InitialId: 20,
+	}
+	readers, err := dr.Split(3)
+	assert.NoError(t, err)
+	assert.Len(t, readers, 3)
+	assert.Equal(t, []int64{50, 80, 110}, []int64{readers[0].MaxId, readers[1].MaxId, readers[2].MaxId})
+	ids := readPartitionIds(t, readers)
+	assert.Equal(t, []int{30, 30, 30}, []int{len(ids[0]), len(ids[1]), len(ids[2])})
+	assert.Equal(t, []int64{21, 50, 51, 80, 81, 110}, []int64{ids[0][0], ids[0][29], ids[1][0], ids[1][29], ids[2][0], ids[2][29]})
+
+	dr.KeyColumns = []string{"id", "created_at"}
+	_, err = dr.Split(3)
+	assert.Error(t, err)
+}
+
+// TestDataReaderSplitBetween verifies that the range of the query type "between" calculated
+// from the range column is split into partitions on the step boundaries.
+func TestDataReaderSplitBetween(t *testing.T) {
+	dr := DataReader{
+		AppDb:            &AppDb{Driver: DRIVER_SQLITE, Dsn: prepareSqlitePartitionDb(t)},
+		Query:            "SELECT * FROM test WHERE created_at >= '{{start}}' AND created_at < '{{end}}'",
+		QueryType:        QUERY_TYPE_BETWEEN,
+		BetweenColumn:    "created_at",
+		BetweenChunkRows: 10,
+	}
+	readers, err := dr.Split(4)
+	assert.NoError(t, err)
+	assert.Len(t, readers, 4)
+	assert.Equal(t, "2025-01-01 00:00:00", readers[0].BetweenStart)
+	assert.Equal(t, "2025-01-01 01:39:01", readers[3].BetweenEnd)
+	for i := 1; i < len(readers); i++ {
+		assert.Equal(t, readers[i-1].BetweenEnd, readers[i].BetweenStart)
+		assert.Equal(t, readers[0].BetweenStep, readers[i].BetweenStep)
+	}
+	ids := readPartitionIds(t, readers)
+	var all []int64
+	for _, partition := range ids {
+		assert.NotEmpty(t, partition)
+		all = append(all, partition...)
+	}
+	assert.Len(t, all, 100)
+	assert.Equal(t, int64(11), all[0])
+	assert.Equal(t, int64(110), all[len(all)-1])
+}
@@ -0,0 +1,55 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+// DialectInterface is an interface for generating SQL specific to a database.
+type DialectInterface interface {
+	// GetName returns the name of the database driver the dialect is used for.
+	GetName() string
+
+	// QuoteIdentifier returns the identifier (column or table name) quoted for use in SQL statements.
+	QuoteIdentifier(name string) string
+
+	// Placeholder returns the placeholder for the prepared statement argument with the given 1-based index.
+	Placeholder(index int) string
+
+	// QuoteString returns the string escaped and quoted as a SQL string literal.
+	QuoteString(str string) string
+
+	// QuoteBytes returns the byte slice escaped and quoted as a SQL literal.
+	QuoteBytes(bytes []byte) string
+
+	// LimitOffset returns the clause that limits the result set to the given number of rows
+	// starting from the given offset.
+	LimitOffset(limit int64, offset int64) string
+
+	// EstimateRows returns the query that selects the estimated number of rows in the given table
+	// from the table statistics, or an empty string if the database does not provide the estimate.
+	EstimateRows(table string) string
+
+	// ConnectionId returns the query that selects the id of the current connection,
+	// or an empty string if the database does not support cancelling the queries of a connection.
+	ConnectionId() string
+
+	// KillQuery returns the statement that cancels the query running on the connection with the given id
+	// from another connection, or an empty string if the database does not support it.
+	KillQuery(connectionId int64) string
+
+	// IsTransientError returns true if the given error of the database is temporary, for example a deadlock,
+	// a lock wait timeout or a lost connection, so the failed operation may succeed when it is repeated.
+	IsTransientError(err error) bool
+
+	// IsNotAppliedError returns true if the given transient error proves that the failed statement has not been
+	// applied, for example a deadlock or a lock wait timeout, so a write outside a transaction can be repeated
+	// without writing the rows twice. A connection lost while the statement is executed does not prove it.
+	IsNotAppliedError(err error) bool
+
+	// MaxPlaceholders returns the max number of placeholders in one prepared statement,
+	// or 0 if the number is not limited.
+	MaxPlaceholders() int
+
+	// MaxStatementSize returns the query that selects the max size in bytes of one statement sent to the server,
+	// or an empty string if the database does not limit it below the size of the batches.
+	MaxStatementSize() string
+}
@@ -0,0 +1,115 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+// DialectClickHouse is an implementation of the DialectInterface for ClickHouse databases.
+type DialectClickHouse struct{}
+
+// GetName returns the name of the ClickHouse database driver.
+func (d *DialectClickHouse) GetName() string {
+	return DRIVER_CLICKHOUSE
+}
+
+// QuoteIdentifier returns the identifier wrapped in backticks.
+// Backticks and backslashes inside the identifier are escaped with a backslash.
+func (d *DialectClickHouse) QuoteIdentifier(name string) string {
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(strings.ReplaceAll(name, "\\", "\\\\"), "`", "\\`"))
+}
+
+// Placeholder returns the question mark placeholder, ClickHouse placeholders are not numbered.
+func (d *DialectClickHouse) Placeholder(index int) string {
+	return "?"
+}
+
+// QuoteString returns the string wrapped in single quotes.
+// Backslashes and single quotes are escaped with a backslash.
+func (d *DialectClickHouse) QuoteString(str string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(strings.ReplaceAll(str, "\\", "\\\\"), "'", "\\'"))
+}
+
+// QuoteBytes returns the byte slice quoted as a string literal.
+func (d *DialectClickHouse) QuoteBytes(bytes []byte) string {
+	return d.QuoteString(string(bytes))
+}
+
+// LimitOffset returns the LIMIT and OFFSET clause.
+func (d *DialectClickHouse) LimitOffset(limit int64, offset int64) string {
+	return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+}
+
+// EstimateRows returns the query that selects the number of rows from system.tables.
+// If the table name is not qualified with the database name, the current database is used.
+func (d *DialectClickHouse) EstimateRows(table string) string {
+	schema, name := SplitTableName(table)
+	schemaCondition := "currentDatabase()"
+	if schema != "" {
+		schemaCondition = d.QuoteString(schema)
+	}
+	return fmt.Sprintf("SELECT total_rows FROM system.tables WHERE database = %s AND name = %s", schemaCondition, d.QuoteString(name))
+}
+
+// ConnectionId returns an empty string, as ClickHouse queries are cancelled by the query id, not by the connection.
+func (d *DialectClickHouse) ConnectionId() string {
+	return ""
+}
+
+// KillQuery returns an empty string, as ClickHouse queries are cancelled by the query id, not by the connection.
+func (d *DialectClickHouse) KillQuery(connectionId int64) string {
+	return ""
+}
+
+// Codes of the ClickHouse exceptions that are transient. UNKNOWN_STATUS_OF_INSERT (319) is not transient,
+// as the server may have applied the insert, so the retry could write the rows twice.
+var clickHouseTransientErrors = map[int32]bool{
+	202: true, // TOO_MANY_SIMULTANEOUS_QUERIES
+	209: true, // SOCKET_TIMEOUT
+	210: true, // NETWORK_ERROR
+	242: true, // TABLE_IS_READ_ONLY
+	252: true, // TOO_MANY_PARTS
+}
+
+// IsTransientError returns true for too many parts, too many simultaneous queries and network errors.
+func (d *DialectClickHouse) IsTransientError(err error) bool {
+	var exception *clickhouse.Exception
+	if errors.As(err, &exception) {
+		return clickHouseTransientErrors[exception.Code]
+	}
+	return false
+}
+
+// Codes of the ClickHouse exceptions with which the server rejects the query before applying it.
+var clickHouseNotAppliedErrors = map[int32]bool{
+	202: true, // TOO_MANY_SIMULTANEOUS_QUERIES
+	242: true, // TABLE_IS_READ_ONLY
+	252: true, // TOO_MANY_PARTS
+}
+
+// IsNotAppliedError returns true for too many parts, too many simultaneous queries and read only tables.
+// After a network error the status of the insert is unknown.
+func (d *DialectClickHouse) IsNotAppliedError(err error) bool {
+	var exception *clickhouse.Exception
+	if errors.As(err, &exception) {
+		return clickHouseNotAppliedErrors[exception.Code]
+	}
+	return false
+}
+
+// MaxPlaceholders returns 0, as the ClickHouse driver binds the arguments on the client.
+func (d *DialectClickHouse) MaxPlaceholders() int {
+	return 0
+}
+
+// MaxStatementSize returns an empty string, as ClickHouse does not parse the data after VALUES
+// as a part of the query, so it is not limited by max_query_size.
+func (d *DialectClickHouse) MaxStatementSize() string {
+	return ""
+}
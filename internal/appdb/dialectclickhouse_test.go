@@ -0,0 +1,36 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDialectClickHouseIsTransientError verifies that too many parts and network errors
+// are transient, while other exceptions of the server are not.
+func TestDialectClickHouseIsTransientError(t *testing.T) {
+	d := DialectClickHouse{}
+	assert.True(t, d.IsTransientError(&clickhouse.Exception{Code: 252, Name: "DB::Exception", Message: "Too many parts"}))
+	assert.True(t, d.IsTransientError(fmt.Errorf("error writing to database: %w", &clickhouse.Exception{Code: 210})))
+	assert.False(t, d.IsTransientError(&clickhouse.Exception{Code: 62, Message: "Syntax error"}))
+	// The insert with an unknown status may have been applied
+	assert.False(t, d.IsTransientError(&clickhouse.Exception{Code: 319, Name: "UNKNOWN_STATUS_OF_INSERT"}))
+	assert.False(t, d.IsTransientError(errors.New("syntax error")))
+}
+
+// TestDialectClickHouseIsNotAppliedError verifies that the queries rejected by the server have not been applied,
+// while the status of the insert is unknown after a network error.
+func TestDialectClickHouseIsNotAppliedError(t *testing.T) {
+	d := DialectClickHouse{}
+	assert.True(t, d.IsNotAppliedError(&clickhouse.Exception{Code: 252}))
+	assert.True(t, d.IsNotAppliedError(fmt.Errorf("error preparing batch: %w", &clickhouse.Exception{Code: 202})))
+	assert.False(t, d.IsNotAppliedError(&clickhouse.Exception{Code: 209}))
+	assert.False(t, d.IsNotAppliedError(&clickhouse.Exception{Code: 210}))
+	assert.False(t, d.IsNotAppliedError(errors.New("read: connection reset by peer")))
+}
@@ -0,0 +1,22 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+// DialectFactory is a factory for creating DialectInterface instances.
+type DialectFactory struct{}
+
+// CreateDialect creates a new instance of a dialect based on the specified database driver name.
+// It returns the MySQL dialect for unknown drivers.
+func (f *DialectFactory) CreateDialect(driver string) DialectInterface {
+	switch driver {
+	case DRIVER_CLICKHOUSE:
+		return &DialectClickHouse{}
+	case DRIVER_POSTGRES:
+		return &DialectPostgres{}
+	case DRIVER_SQLITE:
+		return &DialectSqlite{}
+	default:
+		return &DialectMySql{}
+	}
+}
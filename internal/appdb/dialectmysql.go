@@ -0,0 +1,111 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// DialectMySql is an implementation of the DialectInterface for MySQL databases.
+type DialectMySql struct{}
+
+// GetName returns the name of the MySQL database driver.
+func (d *DialectMySql) GetName() string {
+	return DRIVER_MYSQL
+}
+
+// QuoteIdentifier returns the identifier wrapped in backticks.
+// Backticks inside the identifier are doubled.
+func (d *DialectMySql) QuoteIdentifier(name string) string {
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(name, "`", "``"))
+}
+
+// Placeholder returns the question mark placeholder, MySQL placeholders are not numbered.
+func (d *DialectMySql) Placeholder(index int) string {
+	return "?"
+}
+
+// QuoteString returns the string wrapped in single quotes.
+// Single quotes are doubled and backslashes are escaped with a backslash.
+func (d *DialectMySql) QuoteString(str string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(strings.ReplaceAll(str, "'", "''"), "\\", "\\\\"))
+}
+
+// QuoteBytes returns the byte slice quoted as a string literal.
+func (d *DialectMySql) QuoteBytes(bytes []byte) string {
+	return d.QuoteString(string(bytes))
+}
+
+// LimitOffset returns the LIMIT and OFFSET clause.
+func (d *DialectMySql) LimitOffset(limit int64, offset int64) string {
+	return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+}
+
+// EstimateRows returns the query that selects the estimated number of rows from information_schema.TABLES.
+// It is the estimate used by EXPLAIN, which is updated by ANALYZE TABLE.
+// If the table name is not qualified with the database name, the current database is used.
+func (d *DialectMySql) EstimateRows(table string) string {
+	schema, name := SplitTableName(table)
+	schemaCondition := "DATABASE()"
+	if schema != "" {
+		schemaCondition = d.QuoteString(schema)
+	}
+	return fmt.Sprintf("SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = %s AND TABLE_NAME = %s", schemaCondition, d.QuoteString(name))
+}
+
+// ConnectionId returns the query that selects the id of the current connection.
+func (d *DialectMySql) ConnectionId() string {
+	return "SELECT CONNECTION_ID()"
+}
+
+// KillQuery returns the KILL QUERY statement that stops the statement running on the connection,
+// the connection itself is kept.
+func (d *DialectMySql) KillQuery(connectionId int64) string {
+	return fmt.Sprintf("KILL QUERY %d", connectionId)
+}
+
+// Numbers of the MySQL errors that are transient.
+var mySqlTransientErrors = map[uint16]bool{
+	1205: true, // ER_LOCK_WAIT_TIMEOUT
+	1213: true, // ER_LOCK_DEADLOCK
+	1040: true, // ER_CON_COUNT_ERROR
+	2006: true, // CR_SERVER_GONE_ERROR
+	2013: true, // CR_SERVER_LOST
+}
+
+// IsTransientError returns true for deadlocks, lock wait timeouts, too many connections and lost connections.
+func (d *DialectMySql) IsTransientError(err error) bool {
+	var mySqlError *mysql.MySQLError
+	if errors.As(err, &mySqlError) {
+		return mySqlTransientErrors[mySqlError.Number]
+	}
+	return errors.Is(err, mysql.ErrInvalidConn) || strings.Contains(strings.ToLower(err.Error()), "server has gone away")
+}
+
+// IsNotAppliedError returns true for deadlocks, lock wait timeouts and too many connections.
+// The server may have committed the statement before the connection was lost.
+func (d *DialectMySql) IsNotAppliedError(err error) bool {
+	var mySqlError *mysql.MySQLError
+	if errors.As(err, &mySqlError) {
+		switch mySqlError.Number {
+		case 1040, 1205, 1213:
+			return true
+		}
+	}
+	return false
+}
+
+// MaxPlaceholders returns 65535, the max number of placeholders of a MySQL prepared statement.
+func (d *DialectMySql) MaxPlaceholders() int {
+	return 65535
+}
+
+// MaxStatementSize returns the query that selects max_allowed_packet, the max size of a packet sent to the server.
+func (d *DialectMySql) MaxStatementSize() string {
+	return "SELECT @@max_allowed_packet"
+}
@@ -0,0 +1,79 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDialectMySqlQuoteIdentifier verifies that identifiers are wrapped in backticks
+// and backticks inside the identifier are doubled.
+func TestDialectMySqlQuoteIdentifier(t *testing.T) {
+	d := DialectMySql{}
+	assert.Equal(t, "`id`", d.QuoteIdentifier("id"))
+	assert.Equal(t, "`a``b`", d.QuoteIdentifier("a`b"))
+}
+
+// TestDialectFactory verifies that the dialect factory creates the dialect for the given driver
+// and falls back to the MySQL dialect for unknown drivers.
+func TestDialectFactory(t *testing.T) {
+	factory := DialectFactory{}
+	assert.Equal(t, DRIVER_POSTGRES, factory.CreateDialect(DRIVER_POSTGRES).GetName())
+	assert.Equal(t, DRIVER_CLICKHOUSE, factory.CreateDialect(DRIVER_CLICKHOUSE).GetName())
+	assert.Equal(t, DRIVER_MYSQL, factory.CreateDialect("unknown").GetName())
+}
+
+// TestDialectKillQuery verifies the statements that kill the query of a connection
+// for the dialects that support it.
+func TestDialectKillQuery(t *testing.T) {
+	factory := DialectFactory{}
+	mysql := factory.CreateDialect(DRIVER_MYSQL)
+	assert.Equal(t, "SELECT CONNECTION_ID()", mysql.ConnectionId())
+	assert.Equal(t, "KILL QUERY 42", mysql.KillQuery(42))
+	postgres := factory.CreateDialect(DRIVER_POSTGRES)
+	assert.Equal(t, "SELECT pg_cancel_backend(42)", postgres.KillQuery(42))
+	assert.Empty(t, factory.CreateDialect(DRIVER_SQLITE).ConnectionId())
+	assert.Empty(t, factory.CreateDialect(DRIVER_CLICKHOUSE).ConnectionId())
+}
+
+// TestDialectStatementLimits verifies the max number of placeholders of the dialects
+// and that only MySQL reads the max statement size from the server.
+func TestDialectStatementLimits(t *testing.T) {
+	factory := DialectFactory{}
+	assert.Equal(t, 65535, factory.CreateDialect(DRIVER_MYSQL).MaxPlaceholders())
+	assert.Equal(t, 65535, factory.CreateDialect(DRIVER_POSTGRES).MaxPlaceholders())
+	assert.Equal(t, 32766, factory.CreateDialect(DRIVER_SQLITE).MaxPlaceholders())
+	assert.Equal(t, 0, factory.CreateDialect(DRIVER_CLICKHOUSE).MaxPlaceholders())
+	assert.Equal(t, "SELECT @@max_allowed_packet", factory.CreateDialect(DRIVER_MYSQL).MaxStatementSize())
+	assert.Empty(t, factory.CreateDialect(DRIVER_POSTGRES).MaxStatementSize())
+}
+
+// TestDialectMySqlIsTransientError verifies that deadlocks, lock wait timeouts and lost connections
+// are transient, while other errors of the server are not.
+func TestDialectMySqlIsTransientError(t *testing.T) {
+	d := DialectMySql{}
+	assert.True(t, d.IsTransientError(&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}))
+	assert.True(t, d.IsTransientError(fmt.Errorf("error writing to database: %w", &mysql.MySQLError{Number: 1205})))
+	assert.True(t, d.IsTransientError(mysql.ErrInvalidConn))
+	assert.True(t, d.IsTransientError(errors.New("MySQL server has gone away")))
+	assert.False(t, d.IsTransientError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}))
+	assert.False(t, d.IsTransientError(errors.New("syntax error")))
+}
+
+// TestDialectMySqlIsNotAppliedError verifies that deadlocks, lock wait timeouts and too many connections
+// prove that the statement has not been applied, while lost connections do not.
+func TestDialectMySqlIsNotAppliedError(t *testing.T) {
+	d := DialectMySql{}
+	assert.True(t, d.IsNotAppliedError(&mysql.MySQLError{Number: 1213}))
+	assert.True(t, d.IsNotAppliedError(fmt.Errorf("error writing to database: %w", &mysql.MySQLError{Number: 1205})))
+	assert.True(t, d.IsNotAppliedError(&mysql.MySQLError{Number: 1040}))
+	assert.False(t, d.IsNotAppliedError(&mysql.MySQLError{Number: 2013}))
+	assert.False(t, d.IsNotAppliedError(mysql.ErrInvalidConn))
+	assert.False(t, d.IsNotAppliedError(errors.New("MySQL server has gone away")))
+}
@@ -0,0 +1,113 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+)
+
+// DialectPostgres is an implementation of the DialectInterface for PostgreSQL databases.
+type DialectPostgres struct{}
+
+// GetName returns the name of the PostgreSQL database driver.
+func (d *DialectPostgres) GetName() string {
+	return DRIVER_POSTGRES
+}
+
+// QuoteIdentifier returns the identifier wrapped in double quotes.
+// Double quotes inside the identifier are doubled.
+func (d *DialectPostgres) QuoteIdentifier(name string) string {
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(name, `"`, `""`))
+}
+
+// Placeholder returns the numbered placeholder in the form $1, $2, ..., $n.
+func (d *DialectPostgres) Placeholder(index int) string {
+	return fmt.Sprintf("$%d", index)
+}
+
+// QuoteString returns the string wrapped in single quotes.
+// Single quotes are doubled, backslashes are not escaped (standard_conforming_strings is on by default).
+func (d *DialectPostgres) QuoteString(str string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(str, "'", "''"))
+}
+
+// QuoteBytes returns the byte slice quoted as a string literal if it is a valid UTF-8 string,
+// otherwise it returns the bytes in the bytea hex format '\x...'.
+func (d *DialectPostgres) QuoteBytes(bytes []byte) string {
+	if utf8.Valid(bytes) {
+		return d.QuoteString(string(bytes))
+	}
+	return fmt.Sprintf("'\\x%s'", hex.EncodeToString(bytes))
+}
+
+// LimitOffset returns the LIMIT and OFFSET clause.
+func (d *DialectPostgres) LimitOffset(limit int64, offset int64) string {
+	return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+}
+
+// EstimateRows returns the query that selects the estimated number of rows from pg_class.
+// It is the estimate used by EXPLAIN, which is updated by VACUUM and ANALYZE.
+func (d *DialectPostgres) EstimateRows(table string) string {
+	schema, name := SplitTableName(table)
+	if schema != "" {
+		name = d.QuoteIdentifier(schema) + "." + d.QuoteIdentifier(name)
+	} else {
+		name = d.QuoteIdentifier(name)
+	}
+	return fmt.Sprintf("SELECT reltuples::bigint FROM pg_class WHERE oid = to_regclass(%s)", d.QuoteString(name))
+}
+
+// ConnectionId returns the query that selects the process id of the server process of the current connection.
+func (d *DialectPostgres) ConnectionId() string {
+	return "SELECT pg_backend_pid()"
+}
+
+// KillQuery returns the query that cancels the query running in the server process with the given id.
+func (d *DialectPostgres) KillQuery(connectionId int64) string {
+	return fmt.Sprintf("SELECT pg_cancel_backend(%d)", connectionId)
+}
+
+// IsTransientError returns true for serialization failures, deadlocks, too many connections
+// and the errors of the connection class 08.
+func (d *DialectPostgres) IsTransientError(err error) bool {
+	var pqError *pq.Error
+	if !errors.As(err, &pqError) {
+		return false
+	}
+	switch pqError.Code {
+	case "40001", "40P01", "53300", "57P03":
+		return true
+	}
+	return pqError.Code.Class() == "08"
+}
+
+// IsNotAppliedError returns true for serialization failures, deadlocks, too many connections
+// and the server that is starting. The server may have committed the statement before the connection was lost.
+func (d *DialectPostgres) IsNotAppliedError(err error) bool {
+	var pqError *pq.Error
+	if !errors.As(err, &pqError) {
+		return false
+	}
+	switch pqError.Code {
+	case "40001", "40P01", "53300", "57P03":
+		return true
+	}
+	return false
+}
+
+// MaxPlaceholders returns 65535, the max number of parameters of a PostgreSQL statement.
+func (d *DialectPostgres) MaxPlaceholders() int {
+	return 65535
+}
+
+// MaxStatementSize returns an empty string, as PostgreSQL accepts statements up to 1 GB.
+func (d *DialectPostgres) MaxStatementSize() string {
+	return ""
+}
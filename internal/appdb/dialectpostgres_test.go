@@ -0,0 +1,71 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDialectPostgresQuoteIdentifier verifies that identifiers are wrapped in double quotes
+// and double quotes inside the identifier are doubled.
+func TestDialectPostgresQuoteIdentifier(t *testing.T) {
+	d := DialectPostgres{}
+	assert.Equal(t, `"id"`, d.QuoteIdentifier("id"))
+	assert.Equal(t, `"a""b"`, d.QuoteIdentifier(`a"b`))
+}
+
+// TestDialectPostgresQuoteString verifies that single quotes are doubled and backslashes are kept as is.
+func TestDialectPostgresQuoteString(t *testing.T) {
+	d := DialectPostgres{}
+	assert.Equal(t, `'test\test''test'`, d.QuoteString(`test\test'test`))
+}
+
+// TestDialectPostgresQuoteBytes verifies that valid UTF-8 bytes are quoted as a string
+// and binary data is quoted in the bytea hex format.
+func TestDialectPostgresQuoteBytes(t *testing.T) {
+	d := DialectPostgres{}
+	assert.Equal(t, `'test'`, d.QuoteBytes([]byte("test")))
+	assert.Equal(t, `'\x00ff'`, d.QuoteBytes([]byte{0x00, 0xff}))
+}
+
+// TestFormatterPostgresPlaceholders verifies that the formatter with the PostgreSQL dialect
+// builds numbered placeholders that continue from the given index in multi-row statements.
+func TestFormatterPostgresPlaceholders(t *testing.T) {
+	formatter := Formatter{Dialect: &DialectPostgres{}}
+	assert.Equal(t, "$1, $2, $3", formatter.BuildInsertPlaceholders(3))
+	assert.Equal(t, "$4, $5, $6", formatter.GetInsertStatement(STATEMENT_TYPE_PREPARED, []any{1, 2, 3}, 4))
+}
+
+// TestQueryProcessorLimitOffsetPostgres verifies that the limit-offset query processor
+// uses the LIMIT and OFFSET clause of the PostgreSQL dialect.
+func TestQueryProcessorLimitOffsetPostgres(t *testing.T) {
+	factory := QueryProcessorFactory{Dialect: &DialectPostgres{}}
+	qp := factory.CreateQueryProcessor(QUERY_TYPE_LIMIT_OFFSET, "SELECT * FROM table ORDER BY id", map[string]any{"limit": int64(10)})
+	assert.Equal(t, "SELECT * FROM table ORDER BY id LIMIT 10 OFFSET 0;", qp.ProcessQuery())
+}
+
+// TestDialectPostgresIsTransientError verifies that serialization failures, deadlocks and connection errors
+// are transient, while other errors of the server are not.
+func TestDialectPostgresIsTransientError(t *testing.T) {
+	d := DialectPostgres{}
+	assert.True(t, d.IsTransientError(&pq.Error{Code: "40001"}))
+	assert.True(t, d.IsTransientError(&pq.Error{Code: "40P01"}))
+	assert.True(t, d.IsTransientError(&pq.Error{Code: "08006"}))
+	assert.False(t, d.IsTransientError(&pq.Error{Code: "23505"}))
+	assert.False(t, d.IsTransientError(errors.New("syntax error")))
+}
+
+// TestDialectPostgresIsNotAppliedError verifies that serialization failures and deadlocks prove that
+// the statement has not been applied, while connection errors do not.
+func TestDialectPostgresIsNotAppliedError(t *testing.T) {
+	d := DialectPostgres{}
+	assert.True(t, d.IsNotAppliedError(&pq.Error{Code: "40001"}))
+	assert.True(t, d.IsNotAppliedError(&pq.Error{Code: "40P01"}))
+	assert.False(t, d.IsNotAppliedError(&pq.Error{Code: "08006"}))
+	assert.False(t, d.IsNotAppliedError(errors.New("syntax error")))
+}
@@ -0,0 +1,98 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DialectSqlite is an implementation of the DialectInterface for SQLite databases.
+type DialectSqlite struct{}
+
+// GetName returns the name of the SQLite database driver.
+func (d *DialectSqlite) GetName() string {
+	return DRIVER_SQLITE
+}
+
+// QuoteIdentifier returns the identifier wrapped in double quotes.
+// Double quotes inside the identifier are doubled.
+func (d *DialectSqlite) QuoteIdentifier(name string) string {
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(name, `"`, `""`))
+}
+
+// Placeholder returns the question mark placeholder, SQLite placeholders are numbered by position.
+func (d *DialectSqlite) Placeholder(index int) string {
+	return "?"
+}
+
+// QuoteString returns the string wrapped in single quotes.
+// Single quotes are doubled, backslashes have no special meaning in SQLite.
+func (d *DialectSqlite) QuoteString(str string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(str, "'", "''"))
+}
+
+// QuoteBytes returns the byte slice quoted as a string literal if it is a valid UTF-8 string,
+// otherwise it returns the bytes as a BLOB literal X'...'.
+func (d *DialectSqlite) QuoteBytes(bytes []byte) string {
+	if utf8.Valid(bytes) {
+		return d.QuoteString(string(bytes))
+	}
+	return fmt.Sprintf("X'%s'", hex.EncodeToString(bytes))
+}
+
+// LimitOffset returns the LIMIT and OFFSET clause.
+func (d *DialectSqlite) LimitOffset(limit int64, offset int64) string {
+	return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+}
+
+// EstimateRows returns an empty string, as SQLite does not keep the number of rows in the table statistics.
+func (d *DialectSqlite) EstimateRows(table string) string {
+	return ""
+}
+
+// ConnectionId returns an empty string, as SQLite runs in the process and has no server connections.
+func (d *DialectSqlite) ConnectionId() string {
+	return ""
+}
+
+// KillQuery returns an empty string, as SQLite runs in the process and a cancelled query stops at once.
+func (d *DialectSqlite) KillQuery(connectionId int64) string {
+	return ""
+}
+
+// Primary result codes of the SQLite errors that are transient.
+const (
+	SQLITE_BUSY   = 5
+	SQLITE_LOCKED = 6
+)
+
+// IsTransientError returns true if the database or a table is locked by another connection.
+func (d *DialectSqlite) IsTransientError(err error) bool {
+	var sqliteError interface{ Code() int }
+	if errors.As(err, &sqliteError) {
+		code := sqliteError.Code() & 0xff
+		return code == SQLITE_BUSY || code == SQLITE_LOCKED
+	}
+	return false
+}
+
+// IsNotAppliedError returns true for all transient errors, as a statement that cannot get the lock
+// of the database or a table is not applied.
+func (d *DialectSqlite) IsNotAppliedError(err error) bool {
+	return d.IsTransientError(err)
+}
+
+// MaxPlaceholders returns 32766, the default max number of host parameters of SQLite since version 3.32.0.
+func (d *DialectSqlite) MaxPlaceholders() int {
+	return 32766
+}
+
+// MaxStatementSize returns an empty string, as SQLite accepts statements up to 1 GB.
+func (d *DialectSqlite) MaxStatementSize() string {
+	return ""
+}
@@ -0,0 +1,77 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDialectSqliteQuote verifies that identifiers are wrapped in double quotes,
+// single quotes in strings are doubled and binary data is quoted as a BLOB literal.
+func TestDialectSqliteQuote(t *testing.T) {
+	d := DialectSqlite{}
+	assert.Equal(t, `"a""b"`, d.QuoteIdentifier(`a"b`))
+	assert.Equal(t, `'test\test''test'`, d.QuoteString(`test\test'test`))
+	assert.Equal(t, `'test'`, d.QuoteBytes([]byte("test")))
+	assert.Equal(t, `X'00ff'`, d.QuoteBytes([]byte{0x00, 0xff}))
+}
+
+// TestAppDbSqliteTransaction verifies that the rows inserted in a rolled back transaction are discarded
+// and the rows inserted in a committed transaction are saved.
+func TestAppDbSqliteTransaction(t *testing.T) {
+	ad := AppDb{Driver: DRIVER_SQLITE, Dsn: filepath.Join(t.TempDir(), "test.db")}
+	assert.Nil(t, ad.Open())
+	defer ad.Close()
+	_, err := ad.Exec("CREATE TABLE " + TEST_TBL_NAME + " (id INTEGER);")
+	assert.Nil(t, err)
+
+	assert.Nil(t, ad.Begin())
+	assert.True(t, ad.InTransaction())
+	_, err = ad.Exec(TEST_INSERT_INTO_RAW_SQL)
+	assert.Nil(t, err)
+	assert.Nil(t, ad.Rollback())
+	assert.False(t, ad.InTransaction())
+	count, err := ad.GetScalar(TEST_SELECT_FROM_COUNT_SQL)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+
+	assert.Nil(t, ad.Begin())
+	_, err = ad.PrepareExec(TEST_INSERT_INTO_SQL, 1, 2, 3)
+	assert.Nil(t, err)
+	assert.Nil(t, ad.Commit())
+	count, err = ad.GetScalar(TEST_SELECT_FROM_COUNT_SQL)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+// TestDialectSqliteIsTransientError verifies that the error of a locked database is transient.
+func TestDialectSqliteIsTransientError(t *testing.T) {
+	d := DialectSqlite{}
+	ad := AppDb{Driver: DRIVER_SQLITE, Dsn: filepath.Join(t.TempDir(), "test.db")}
+	assert.Nil(t, ad.Open())
+	defer ad.Close()
+	_, err := ad.Exec("CREATE TABLE " + TEST_TBL_NAME + " (id INTEGER);")
+	assert.Nil(t, err)
+	unlock := lockSqliteDb(t, ad.Dsn)
+	_, err = ad.Exec(TEST_INSERT_INTO_RAW_SQL)
+	unlock()
+	assert.True(t, d.IsTransientError(err), err)
+	_, err = ad.Exec("SELECT * FROM unknown")
+	assert.False(t, d.IsTransientError(err), err)
+	assert.False(t, d.IsTransientError(errors.New("syntax error")))
+}
+
+// TestAppDbGetMaxStatementSize verifies that the max statement size is 0 for the databases without the limit.
+func TestAppDbGetMaxStatementSize(t *testing.T) {
+	db := AppDb{Driver: DRIVER_SQLITE, Dsn: ":memory:"}
+	assert.Nil(t, db.Open())
+	defer db.Close()
+	size, err := db.GetMaxStatementSize()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), size)
+}
@@ -7,10 +7,35 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
+)
+
+// Constants for formatting values.
+const (
+	TIME_LAYOUT = "2006-01-02 15:04:05.999999"
 )
 
 // Formatter provides methods for formatting database-related operations like insert statements and value formatting.
 type Formatter struct {
+	// SQL dialect of the destination database. If nil, the MySQL dialect is used.
+	Dialect DialectInterface
+}
+
+// getDialect returns the dialect of the formatter or the MySQL dialect if the dialect is not set.
+func (f *Formatter) getDialect() DialectInterface {
+	if f.Dialect == nil {
+		return &DialectMySql{}
+	}
+	return f.Dialect
+}
+
+// QuoteIdentifiers returns a copy of the given identifiers (column names) quoted according to the dialect.
+func (f *Formatter) QuoteIdentifiers(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = f.getDialect().QuoteIdentifier(name)
+	}
+	return quoted
 }
 
 // AppendInitialInsert appends an initial SQL INSERT command to the buffer.
@@ -34,28 +59,37 @@ func (f *Formatter) GetInsertCommand(command string, table string, columns []str
 }
 
 // GetInsertStatement constructs and returns an SQL INSERT statement string.
-// It takes the statement type and values as parameters.
+// It takes the statement type, values and the 1-based index of the first placeholder as parameters.
 // If the statement type is STATEMENT_PREPARED, it builds placeholders for the number of values provided.
 // Otherwise, it formats the values according to the database type and returns the formatted string.
-func (f *Formatter) GetInsertStatement(statement string, values []any) string {
+func (f *Formatter) GetInsertStatement(statement string, values []any, argIndex int) string {
 	if statement == STATEMENT_TYPE_PREPARED {
-		return f.BuildInsertPlaceholders(len(values))
+		return f.BuildInsertPlaceholdersFrom(len(values), argIndex)
 	}
 	return f.FormatRowValues(values)
 }
 
 // FormatValue formats a given value for SQL insertion based on its type.
-// - For byte slices and strings, it escapes single quotes and backslashes and wraps the value in single quotes.
+// - For byte slices and strings, it escapes and quotes the value according to the dialect.
 // - For integer types, it converts the value to a string representation of the number.
 // - For float types, it converts the value to a string representation with no unnecessary precision.
+// - For booleans, it returns the SQL TRUE or FALSE keyword.
+// - For time values, it returns the date and time in the format "YYYY-MM-DD HH:MM:SS.ffffff" wrapped in single quotes.
 // - For nil, it returns the SQL NULL keyword.
 // - For all other types, it uses the default string representation wrapped in single quotes.
 func (f *Formatter) FormatValue(val any) string {
 	switch v := val.(type) {
 	case []byte:
-		return fmt.Sprintf("'%s'", strings.ReplaceAll(strings.ReplaceAll(string(v), "'", "''"), "\\", "\\\\"))
+		return f.getDialect().QuoteBytes(v)
 	case string:
-		return fmt.Sprintf("'%s'", strings.ReplaceAll(strings.ReplaceAll(v, "'", "''"), "\\", "\\\\"))
+		return f.getDialect().QuoteString(v)
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case time.Time:
+		return fmt.Sprintf("'%s'", v.Format(TIME_LAYOUT))
 	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
 		return fmt.Sprintf("%d", v)
 	case float32:
@@ -83,7 +117,19 @@ func (f *Formatter) FormatRowValues(values []any) string {
 }
 
 // BuildInsertPlaceholders builds and returns a string of placeholders for a SQL INSERT statement.
-// It takes the number of columns as a parameter and returns a string of the form "?, ?, ..., ?".
+// It takes the number of columns as a parameter and returns a string of the form "?, ?, ..., ?"
+// or "$1, $2, ..., $n" depending on the dialect.
 func (f *Formatter) BuildInsertPlaceholders(columnCount int) string {
-	return strings.Repeat("?, ", columnCount-1) + "?"
+	return f.BuildInsertPlaceholdersFrom(columnCount, 1)
+}
+
+// BuildInsertPlaceholdersFrom builds and returns a string of placeholders for a SQL INSERT statement.
+// It takes the number of columns and the 1-based index of the first placeholder as parameters.
+// The index is used by dialects with numbered placeholders to continue numbering in multi-row statements.
+func (f *Formatter) BuildInsertPlaceholdersFrom(columnCount int, argIndex int) string {
+	placeholders := make([]string, columnCount)
+	for i := range placeholders {
+		placeholders[i] = f.getDialect().Placeholder(argIndex + i)
+	}
+	return strings.Join(placeholders, ", ")
 }
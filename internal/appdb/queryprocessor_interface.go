@@ -16,4 +16,8 @@ type QueryProcessorInterface interface {
 
 	// ProcessQuery processes the database query and returns the result as a string.
 	ProcessQuery() string
+
+	// GetState returns the position of the last processed query as key-value pairs.
+	// The keys are the same as the keys accepted by SetValue.
+	GetState() map[string]any
 }
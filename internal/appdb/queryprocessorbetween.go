@@ -4,6 +4,7 @@
 package appdb
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -12,11 +13,21 @@ import (
 // QueryProcessorBetween is a struct that implements the QueryProcessorInterface interface
 // for processing SQL queries with BETWEEN clauses for date ranges.
 type QueryProcessorBetween struct {
-	Query        string
-	Start        string
-	End          string
-	Step         string
+	Query string
+	Start string
+	End   string
+	Step  string
+	// Column of the range. If set, InitRange calculates the range values that are not set
+	// from the minimum and maximum values of the column in the source table.
+	Column string
+	// Target number of rows in one range used by InitRange to calculate the step
+	ChunkRows int64
+	// Source of the number of rows used by InitRange: "count" (default) or "estimate"
+	// See: ROW_COUNT_COUNT, ROW_COUNT_ESTIMATE
+	RowCount     string
 	currentStart string
+	// Start value of the range used in the last processed query
+	queryStart string
 }
 
 // Return the type name for a query processor.
@@ -32,6 +43,7 @@ func (q *QueryProcessorBetween) InitQuery() QueryProcessorInterface {
 	q.End = ""
 	q.Step = ""
 	q.currentStart = ""
+	q.queryStart = ""
 	return q
 }
 
@@ -44,6 +56,12 @@ func (q *QueryProcessorBetween) SetValue(key string, value any) QueryProcessorIn
 		q.End = value.(string)
 	case "step":
 		q.Step = value.(string)
+	case "column":
+		q.Column = value.(string)
+	case "chunk_rows":
+		q.ChunkRows = value.(int64)
+	case "row_count":
+		q.RowCount = value.(string)
 	}
 	return q
 }
@@ -63,6 +81,7 @@ func (q *QueryProcessorBetween) ProcessQuery() string {
 	} else {
 		start, end = q.getBetweenDates()
 	}
+	q.queryStart = start
 	return strings.ReplaceAll(strings.ReplaceAll(trimmedQuery, "{{start}}", start), "{{end}}", end)
 }
 
@@ -144,3 +163,164 @@ func (q *QueryProcessorBetween) getBetweenDates() (string, string) {
 	}
 	return currentStart.Format(DATE_TIME_LAYOUT), currentEnd.Format(DATE_TIME_LAYOUT)
 }
+
+// GetState implements the QueryProcessorInterface and returns the start value
+// of the range used in the last processed query and the end and step values.
+func (q *QueryProcessorBetween) GetState() map[string]any {
+	return map[string]any{"start": q.queryStart, "end": q.End, "step": q.Step}
+}
+
+// InitRange calculates the range values that are not set if the Column field is set.
+// The start and end values are the minimum and maximum values of the column in the table of the query,
+// the end value is increased by 1 (one second for dates) so the last row is included by both
+// "BETWEEN {{start}} AND {{end}}" and ">= {{start}} AND < {{end}}" conditions.
+// The step is calculated so that each range contains about ChunkRows rows, assuming that the rows are
+// distributed evenly. The number of rows is counted or taken from the table statistics, see RowCount.
+// It returns an error if the table of the query or the type of the column values is not supported.
+func (q *QueryProcessorBetween) InitRange(db *AppDb) error {
+	if q.Column == "" || (q.Start != "" && q.End != "" && q.Step != "") {
+		return nil
+	}
+	sqlHelper := SqlHelper{Sql: q.Query}
+	table := sqlHelper.GetFromTableName()
+	if table == "" {
+		return fmt.Errorf("table name not found in the query")
+	}
+	if q.Step == "" && q.ChunkRows <= 0 {
+		return fmt.Errorf("rows per range must be set to calculate the range step")
+	}
+	minValue, maxValue, err := GetColumnRange(db, table, q.Column)
+	if err != nil {
+		return err
+	}
+	rows, err := q.getRowCount(db, table)
+	if err != nil {
+		return err
+	}
+	chunks := int64(1)
+	if q.ChunkRows > 0 && rows > q.ChunkRows {
+		chunks = (rows + q.ChunkRows - 1) / q.ChunkRows
+	}
+	if minValue == nil || maxValue == nil {
+		// The table is empty, any valid range returns no rows
+		minValue, maxValue = int64(0), int64(0)
+	}
+	minInt, minIsInt := toRangeInt(minValue)
+	maxInt, maxIsInt := toRangeInt(maxValue)
+	if minIsInt && maxIsInt {
+		step := (maxInt - minInt + chunks) / chunks
+		q.setRange(strconv.FormatInt(minInt, 10), strconv.FormatInt(maxInt+1, 10), strconv.FormatInt(step, 10))
+		return nil
+	}
+	minTime, minIsTime := toRangeTime(minValue)
+	maxTime, maxIsTime := toRangeTime(maxValue)
+	if minIsTime && maxIsTime {
+		span := maxTime.Sub(minTime) + time.Second
+		// The step is rounded up to whole seconds, as the dates are formatted without fractional seconds
+		step := (span/time.Duration(chunks) + time.Second - 1) / time.Second * time.Second
+		q.setRange(minTime.Format(DATE_TIME_LAYOUT), maxTime.Add(time.Second).Format(DATE_TIME_LAYOUT), step.String())
+		return nil
+	}
+	return fmt.Errorf("unsupported range values of column %s: %v, %v", q.Column, minValue, maxValue)
+}
+
+// GetColumnRange returns the minimum and maximum values of the given column in the given table.
+// Both values are nil if the table is empty.
+func GetColumnRange(db *AppDb, table string, column string) (any, any, error) {
+	quoted := db.GetDialect().QuoteIdentifier(column)
+	row, err := db.QueryRow(fmt.Sprintf("SELECT MIN(%s), MAX(%s) FROM %s", quoted, quoted, table))
+	if err != nil {
+		return nil, nil, err
+	}
+	var minValue, maxValue any
+	if err = row.Scan(&minValue, &maxValue); err != nil {
+		return nil, nil, err
+	}
+	return minValue, maxValue, nil
+}
+
+// setRange sets the Start, End and Step fields to the given values if they are not set.
+func (q *QueryProcessorBetween) setRange(start string, end string, step string) {
+	if q.Start == "" {
+		q.Start = start
+	}
+	if q.End == "" {
+		q.End = end
+	}
+	if q.Step == "" {
+		q.Step = step
+	}
+}
+
+// getRowCount returns the number of rows in the given table. If the RowCount field is "estimate",
+// the estimate from the table statistics is used when the database provides it,
+// otherwise the rows are counted.
+func (q *QueryProcessorBetween) getRowCount(db *AppDb, table string) (int64, error) {
+	if q.RowCount == ROW_COUNT_ESTIMATE {
+		if query := db.GetDialect().EstimateRows(table); query != "" {
+			value, err := db.GetScalar(query)
+			if rows, ok := toRangeInt(value); err == nil && ok && rows > 0 {
+				return rows, nil
+			}
+		}
+	}
+	value, err := db.GetScalar(fmt.Sprintf("SELECT COUNT(*) FROM %s", table))
+	if err != nil {
+		return 0, err
+	}
+	rows, _ := toRangeInt(value)
+	return rows, nil
+}
+
+// toRangeInt converts an integer value or a string or byte slice with an integer to int64.
+// The second return value is false if the value is not an integer.
+func toRangeInt(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case int32:
+		return int64(v), true
+	case int:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case []byte:
+		i, err := strconv.ParseInt(string(v), 10, 64)
+		return i, err == nil
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// toRangeTime converts a time value or a string or byte slice with a date and time to time.Time.
+// The fractional seconds are truncated. The second return value is false if the value is not a date.
+func toRangeTime(value any) (time.Time, bool) {
+	var str string
+	switch v := value.(type) {
+	case time.Time:
+		return v.Truncate(time.Second), true
+	case []byte:
+		str = string(v)
+	case string:
+		str = v
+	default:
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339Nano, str); err == nil {
+		return t.Truncate(time.Second), true
+	}
+	if len(str) >= len(DATE_TIME_LAYOUT) {
+		if t, err := time.Parse(DATE_TIME_LAYOUT, str[:len(DATE_TIME_LAYOUT)]); err == nil {
+			return t, true
+		}
+	}
+	if t, err := time.Parse(time.DateOnly, str); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
@@ -4,7 +4,9 @@
 package appdb
 
 import (
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -67,3 +69,47 @@ func TestQueryProcessorBetweenEndInt(t *testing.T) {
 	actual = qp.ProcessQuery()
 	assert.Equal(t, "SELECT * FROM table WHERE field BETWEEN '5' AND '4' ORDER BY id", actual)
 }
+
+// TestQueryProcessorBetweenGetState verifies that the GetState method of the QueryProcessorBetween struct
+// returns the start value of the range used in the last processed query.
+func TestQueryProcessorBetweenGetState(t *testing.T) {
+	qp := QueryProcessorBetween{Query: "SELECT * FROM table WHERE field BETWEEN '{{start}}' AND '{{end}}' ORDER BY id"}
+	qp.InitQuery()
+	qp.Start = "1"
+	qp.End = "10"
+	qp.Step = "2"
+	qp.ProcessQuery()
+	qp.ProcessQuery()
+	assert.Equal(t, "3", qp.GetState()["start"])
+}
+
+// TestQueryProcessorBetweenInitRange verifies that InitRange calculates the start, end and step values
+// from the minimum and maximum values of the integer and date range columns and the number of rows per range,
+// and that the values set in the config are kept.
+func TestQueryProcessorBetweenInitRange(t *testing.T) {
+	db := AppDb{Driver: DRIVER_SQLITE, Dsn: filepath.Join(t.TempDir(), "range.db")}
+	assert.NoError(t, db.Open())
+	defer db.Close()
+	_, err := db.Exec("CREATE TABLE test (id INTEGER, created_at TEXT)")
+	assert.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		_, err = db.Exec("INSERT INTO test VALUES (?, ?)", i+10, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i)*time.Minute).Format(DATE_TIME_LAYOUT))
+		assert.NoError(t, err)
+	}
+
+	qp := QueryProcessorBetween{Query: "SELECT * FROM test WHERE id >= {{start}} AND id < {{end}}", Column: "id", ChunkRows: 30}
+	assert.NoError(t, qp.InitRange(&db))
+	assert.Equal(t, []string{"10", "110", "25"}, []string{qp.Start, qp.End, qp.Step})
+	assert.Equal(t, "SELECT * FROM test WHERE id >= 10 AND id < 35", qp.ProcessQuery())
+
+	qp = QueryProcessorBetween{Query: "SELECT * FROM test", Column: "created_at", ChunkRows: 50, RowCount: ROW_COUNT_ESTIMATE, Step: "1h"}
+	assert.NoError(t, qp.InitRange(&db))
+	assert.Equal(t, []string{"2025-01-01 00:00:00", "2025-01-01 01:39:01", "1h"}, []string{qp.Start, qp.End, qp.Step})
+
+	qp = QueryProcessorBetween{Query: "SELECT * FROM test", Column: "created_at", ChunkRows: 50}
+	assert.NoError(t, qp.InitRange(&db))
+	assert.Equal(t, "49m31s", qp.Step)
+
+	qp = QueryProcessorBetween{Query: "SELECT * FROM test", Column: "id"}
+	assert.Error(t, qp.InitRange(&db))
+}
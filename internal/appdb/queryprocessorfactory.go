@@ -4,7 +4,10 @@
 package appdb
 
 // QueryProcessorFactory is a factory for creating QueryProcessorInterface instances.
-type QueryProcessorFactory struct{}
+type QueryProcessorFactory struct {
+	// SQL dialect of the source database used by the created query processors.
+	Dialect DialectInterface
+}
 
 // CreateQueryProcessor creates a new instance of a query processor based on the specified query type.
 // It initializes the query processor with the provided SQL query and sets the initial values using the
@@ -14,9 +17,9 @@ func (f *QueryProcessorFactory) CreateQueryProcessor(queryType string, query str
 	var p QueryProcessorInterface
 	switch queryType {
 	case QUERY_TYPE_LIMIT_OFFSET:
-		p = &QueryProcessorLimitOffset{Query: query}
+		p = &QueryProcessorLimitOffset{Query: query, Dialect: f.Dialect}
 	case QUERY_TYPE_ORDERBYID:
-		p = &QueryProcessorOrderByID{Query: query}
+		p = &QueryProcessorOrderByID{Query: query, Dialect: f.Dialect}
 	case QUERY_TYPE_BETWEEN:
 		p = &QueryProcessorBetween{Query: query}
 	default:
@@ -4,7 +4,6 @@
 package appdb
 
 import (
-	"fmt"
 	"strings"
 )
 
@@ -15,6 +14,10 @@ type QueryProcessorLimitOffset struct {
 	Limit     int64
 	Offset    int64
 	MaxOffset int64
+	// SQL dialect of the source database. If nil, the MySQL dialect is used.
+	Dialect DialectInterface
+	// Offset used in the last processed query
+	queryOffset int64
 }
 
 // Return the type name for a simple query processor.
@@ -28,6 +31,7 @@ func (q *QueryProcessorLimitOffset) InitQuery() QueryProcessorInterface {
 	q.Limit = 1000
 	q.Offset = 0
 	q.MaxOffset = 0
+	q.queryOffset = 0
 	return q
 }
 
@@ -44,6 +48,14 @@ func (q *QueryProcessorLimitOffset) SetValue(key string, value any) QueryProcess
 	return q
 }
 
+// getDialect returns the dialect of the query processor or the MySQL dialect if the dialect is not set.
+func (q *QueryProcessorLimitOffset) getDialect() DialectInterface {
+	if q.Dialect == nil {
+		return &DialectMySql{}
+	}
+	return q.Dialect
+}
+
 // ProcessQuery appends a LIMIT and OFFSET clause to the SQL query to enable pagination.
 // It trims any trailing whitespace or semicolons from the original query before appending
 // the LIMIT clause with the specified limit and offset values. The offset is incremented
@@ -52,10 +64,17 @@ func (q *QueryProcessorLimitOffset) SetValue(key string, value any) QueryProcess
 func (q *QueryProcessorLimitOffset) ProcessQuery() string {
 	trimmedQuery := strings.TrimRight(q.Query, " \t\n\r;")
 	if q.MaxOffset > 0 && q.Offset > q.MaxOffset {
-		query := trimmedQuery + " LIMIT 0 OFFSET 0;"
+		query := trimmedQuery + q.getDialect().LimitOffset(0, 0) + ";"
 		return query
 	}
-	query := trimmedQuery + fmt.Sprintf(" LIMIT %d OFFSET %d;", q.Limit, q.Offset)
+	query := trimmedQuery + q.getDialect().LimitOffset(q.Limit, q.Offset) + ";"
+	q.queryOffset = q.Offset
 	q.Offset += q.Limit
 	return query
 }
+
+// GetState implements the QueryProcessorInterface and returns the offset
+// used in the last processed query.
+func (q *QueryProcessorLimitOffset) GetState() map[string]any {
+	return map[string]any{"offset": q.queryOffset}
+}
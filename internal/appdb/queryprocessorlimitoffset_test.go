@@ -58,3 +58,15 @@ func TestQueryProcessorLimitOffsetMaxOffset(t *testing.T) {
 	actual = qp.ProcessQuery()
 	assert.Equal(t, "SELECT * FROM table ORDER BY id LIMIT 0 OFFSET 0;", actual)
 }
+
+// TestQueryProcessorLimitOffsetGetState verifies that the GetState method of the
+// QueryProcessorLimitOffset struct returns the offset used in the last processed query
+// and not the offset of the next query.
+func TestQueryProcessorLimitOffsetGetState(t *testing.T) {
+	qp := QueryProcessorLimitOffset{Query: "SELECT * FROM table ORDER BY id"}
+	qp.InitQuery()
+	qp.Limit = 10
+	qp.ProcessQuery()
+	qp.ProcessQuery()
+	assert.Equal(t, int64(10), qp.GetState()["offset"])
+}
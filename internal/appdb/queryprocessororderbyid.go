@@ -8,11 +8,26 @@ import (
 	"strings"
 )
 
+// Constants for the key placeholders.
+const (
+	KEY_PLACEHOLDER        = "{{key}}"
+	KEY_COLUMN_PLACEHOLDER = "{{key.%s}}"
+	KEY_EMPTY_CONDITION    = "1 = 1"
+)
+
 // QueryProcessorOrderByID is a struct that implements the QueryProcessorInterface interface
 // for processing SQL queries with the {{id}} placeholder replaced by the current value of the Id field.
+// If the key columns are set, the {{key.<name>}} placeholders are replaced by the last values of
+// the key columns and the {{key}} placeholder by the condition that selects the rows after the last key.
 type QueryProcessorOrderByID struct {
 	Query string
 	Id    int64
+	// Names of the key columns in the order of the ORDER BY clause
+	KeyColumns []string
+	// Last values of the key columns formatted as SQL literals by key column name
+	Key map[string]string
+	// SQL dialect of the source database used to quote the key column names. If nil, the MySQL dialect is used.
+	Dialect DialectInterface
 }
 
 // Return the type name for a simple query processor.
@@ -21,25 +36,93 @@ func (q *QueryProcessorOrderByID) GetType() string {
 }
 
 // InitQuery resets the query processor to its initial state by setting the
-// value of the Id field to 0.
+// value of the Id field to 0 and clearing the key values.
 func (q *QueryProcessorOrderByID) InitQuery() QueryProcessorInterface {
 	q.Id = 0
+	q.Key = nil
 	return q
 }
 
 // SetValue sets the value of the specified key in the query processor.
-// The only key supported currently is "id", which is used to set the value of the
-// {{id}} placeholder in the query string.
+// The "id" key sets the value of the {{id}} placeholder in the query string,
+// the "key_columns" key sets the names of the key columns and
+// the "key" key sets the values of the key columns formatted as SQL literals by column name.
 func (q *QueryProcessorOrderByID) SetValue(key string, value any) QueryProcessorInterface {
 	switch strings.ToLower(key) {
 	case "id":
-		q.Id = value.(int64)
+		switch v := value.(type) {
+		case int64:
+			q.Id = v
+		case int:
+			q.Id = int64(v)
+		}
+	case "key_columns":
+		q.KeyColumns, _ = value.([]string)
+	case "key":
+		values, _ := value.(map[string]string)
+		q.Key = make(map[string]string, len(values))
+		for name, val := range values {
+			q.Key[name] = val
+		}
 	}
 	return q
 }
 
 // ProcessQuery implements the QueryProcessorInterface and returns the query string
-// with the {{id}} placeholder replaced by the current value of the Id field.
+// with the {{id}} placeholder replaced by the current value of the Id field
+// and the key placeholders replaced by the current key values.
 func (q *QueryProcessorOrderByID) ProcessQuery() string {
-	return strings.ReplaceAll(q.Query, "{{id}}", fmt.Sprintf("%d", q.Id))
+	query := strings.ReplaceAll(q.Query, "{{id}}", fmt.Sprintf("%d", q.Id))
+	if len(q.KeyColumns) == 0 {
+		return query
+	}
+	query = strings.ReplaceAll(query, KEY_PLACEHOLDER, q.GetKeyCondition())
+	for _, column := range q.KeyColumns {
+		if value, ok := q.Key[column]; ok {
+			query = strings.ReplaceAll(query, fmt.Sprintf(KEY_COLUMN_PLACEHOLDER, column), value)
+		}
+	}
+	return query
+}
+
+// HasKey returns true if the values of all key columns are set.
+func (q *QueryProcessorOrderByID) HasKey() bool {
+	if len(q.KeyColumns) == 0 {
+		return false
+	}
+	for _, column := range q.KeyColumns {
+		if _, ok := q.Key[column]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// GetKeyCondition returns the condition that selects the rows with the key greater than the current key values
+// in the row-value comparison order, for example "(a > 1 OR (a = 1 AND b > 2))" for the key columns a and b.
+// The condition is expanded instead of using the row constructor (a, b) > (1, 2), as not all databases
+// support the row constructor comparison or use indexes for it.
+// If the key values are not set, it returns the condition that is always true.
+func (q *QueryProcessorOrderByID) GetKeyCondition() string {
+	if !q.HasKey() {
+		return KEY_EMPTY_CONDITION
+	}
+	formatter := Formatter{Dialect: q.Dialect}
+	columns := formatter.QuoteIdentifiers(q.KeyColumns)
+	last := len(columns) - 1
+	condition := fmt.Sprintf("%s > %s", columns[last], q.Key[q.KeyColumns[last]])
+	for i := last - 1; i >= 0; i-- {
+		value := q.Key[q.KeyColumns[i]]
+		condition = fmt.Sprintf("%s > %s OR (%s = %s AND %s)", columns[i], value, columns[i], value, condition)
+		if i > 0 {
+			condition = "(" + condition + ")"
+		}
+	}
+	return "(" + condition + ")"
+}
+
+// GetState implements the QueryProcessorInterface and returns the value of the Id field
+// and the key values used in the last processed query.
+func (q *QueryProcessorOrderByID) GetState() map[string]any {
+	return map[string]any{"id": q.Id, "key": q.Key}
 }
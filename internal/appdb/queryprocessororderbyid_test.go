@@ -22,3 +22,16 @@ func TestQueryProcessorOrderById(t *testing.T) {
 	actual = qp.ProcessQuery()
 	assert.Equal(t, "SELECT * FROM table WHERE id > 10 ORDER BY id LIMIT 10", actual)
 }
+
+// TestQueryProcessorOrderByIdKey verifies that the {{key}} placeholder is replaced by the condition that is always true
+// until the key values are set, and then by the expanded row-value comparison of the key columns,
+// and that the {{key.<name>}} placeholders are replaced by the key values.
+func TestQueryProcessorOrderByIdKey(t *testing.T) {
+	qp := QueryProcessorOrderByID{Query: "SELECT * FROM t WHERE {{key}} AND b >= {{key.b}}", Dialect: &DialectPostgres{}}
+	qp.SetValue("key_columns", []string{"a", "b", "c"})
+	assert.Equal(t, "SELECT * FROM t WHERE 1 = 1 AND b >= {{key.b}}", qp.ProcessQuery())
+	qp.SetValue("key", map[string]string{"a": "1", "b": "'x'", "c": "3"})
+	assert.Equal(t, `SELECT * FROM t WHERE ("a" > 1 OR ("a" = 1 AND ("b" > 'x' OR ("b" = 'x' AND "c" > 3)))) AND b >= 'x'`, qp.ProcessQuery())
+	qp.SetValue("key_columns", []string{"a"})
+	assert.Equal(t, `("a" > 1)`, qp.GetKeyCondition())
+}
@@ -33,3 +33,9 @@ func (q *QueryProcessorSimple) SetValue(key string, value any) QueryProcessorInt
 func (q *QueryProcessorSimple) ProcessQuery() string {
 	return q.Query
 }
+
+// GetState implements the QueryProcessorInterface and returns an empty state, as a simple query
+// processor does not have any position in the source data.
+func (q *QueryProcessorSimple) GetState() map[string]any {
+	return map[string]any{}
+}
@@ -0,0 +1,195 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// Constants for retries.
+const (
+	// Delay before the first retry if the base delay is not set
+	RETRY_BASE_DELAY = time.Second
+	// Max delay between retries if the max delay is not set
+	RETRY_MAX_DELAY = time.Minute
+)
+
+// Messages of the connection errors that are retried for all databases.
+var transientMessages = []string{
+	"broken pipe",
+	"connection reset by peer",
+	"connection refused",
+	"unexpected eof",
+}
+
+// RetryPolicy describes how the operations failed with a transient error are retried.
+// The delay before each retry grows exponentially from BaseDelay up to MaxDelay,
+// and a random jitter of up to half of the delay is subtracted, so the retries of
+// parallel operations do not hit the database at the same time.
+type RetryPolicy struct {
+	// Max number of attempts including the first one. If 0 or 1, the operations are not retried.
+	MaxAttempts int
+	// Delay before the first retry. If 0, RETRY_BASE_DELAY is used.
+	BaseDelay time.Duration
+	// Max delay between retries. If 0, RETRY_MAX_DELAY is used.
+	MaxDelay time.Duration
+	// OnRetry is called before waiting for the retry with the number of the failed attempt,
+	// the delay before the retry and the error of the failed attempt. It is used for logging.
+	OnRetry func(attempt int, delay time.Duration, err error)
+}
+
+// CanRetry returns true if the operation failed with the given error at the given 1-based attempt
+// can be retried: the error is transient for the given dialect and the max number of attempts is not reached.
+func (policy *RetryPolicy) CanRetry(attempt int, dialect DialectInterface, err error) bool {
+	return policy != nil && attempt < policy.MaxAttempts && IsTransientError(dialect, err)
+}
+
+// CanRetryWrite returns true if the statement that writes outside a transaction, failed with the given error
+// at the given 1-based attempt, can be retried: it can be retried by CanRetry and the error proves that
+// the statement has not been applied, so the rows are not written twice.
+// See: IsNotAppliedError
+func (policy *RetryPolicy) CanRetryWrite(attempt int, dialect DialectInterface, err error) bool {
+	return policy.CanRetry(attempt, dialect, err) && IsNotAppliedError(dialect, err)
+}
+
+// GetDelay returns the delay before the retry after the given 1-based attempt with the jitter applied.
+func (policy *RetryPolicy) GetDelay(attempt int) time.Duration {
+	base := policy.BaseDelay
+	if base <= 0 {
+		base = RETRY_BASE_DELAY
+	}
+	maxDelay := policy.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = RETRY_MAX_DELAY
+	}
+	delay := base
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxDelay)
+	return delay - rand.N(delay/2+1)
+}
+
+// Wait calls OnRetry and waits for the delay before the retry after the given failed attempt.
+// It returns the cause of the given context if it is done before the delay has passed.
+func (policy *RetryPolicy) Wait(ctx context.Context, attempt int, err error) error {
+	delay := policy.GetDelay(attempt)
+	if policy.OnRetry != nil {
+		policy.OnRetry(attempt, delay, err)
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Do calls the given action until it succeeds or fails with an error that cannot be retried.
+// The action gets the 1-based number of the attempt. It returns the error of the last attempt.
+// See: RetryPolicy.CanRetry
+func (policy *RetryPolicy) Do(ctx context.Context, dialect DialectInterface, action func(attempt int) error) error {
+	return policy.do(ctx, action, func(attempt int, err error) bool {
+		return policy.CanRetry(attempt, dialect, err)
+	})
+}
+
+// DoWrite calls the given action that writes outside a transaction until it succeeds or fails with an error
+// that cannot be retried or does not prove that the action has not been applied. It returns the error
+// of the last attempt.
+// See: RetryPolicy.CanRetryWrite
+func (policy *RetryPolicy) DoWrite(ctx context.Context, dialect DialectInterface, action func(attempt int) error) error {
+	return policy.do(ctx, action, func(attempt int, err error) bool {
+		return policy.CanRetryWrite(attempt, dialect, err)
+	})
+}
+
+// do calls the given action until it succeeds or the given function returns false for its error.
+func (policy *RetryPolicy) do(ctx context.Context, action func(attempt int) error, canRetry func(attempt int, err error) bool) error {
+	for attempt := 1; ; attempt++ {
+		err := action(attempt)
+		if err == nil || !canRetry(attempt, err) {
+			return err
+		}
+		if waitErr := policy.Wait(ctx, attempt, err); waitErr != nil {
+			return err
+		}
+	}
+}
+
+// IsTransientError returns true if the given error is a temporary failure of the database,
+// which may succeed when the operation is repeated, for example a deadlock or a lost connection.
+// Cancellations and timeouts are not transient. The errors specific to a database are
+// classified by its dialect.
+func IsTransientError(dialect DialectInterface, err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrTimeout) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || IsConnectionError(err) {
+		return true
+	}
+	return dialect != nil && dialect.IsTransientError(err)
+}
+
+// notSentError is an error raised before the statement was sent to the database.
+type notSentError struct {
+	err error
+}
+
+// Error returns the message of the wrapped error.
+func (e *notSentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *notSentError) Unwrap() error {
+	return e.err
+}
+
+// NotSent marks the given error as raised before the statement was sent to the database,
+// for example the error of reopening the connection, so the statement has not been applied.
+// It returns nil if the given error is nil.
+func NotSent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &notSentError{err: err}
+}
+
+// IsNotAppliedError returns true if the given error proves that the failed statement has not been applied:
+// the error is raised before the statement was sent, database/sql reports a bad connection, which it does
+// only if nothing has been sent, or the error is classified by the dialect.
+// A connection lost while the statement is executed does not prove it, as the database may have committed it.
+func IsNotAppliedError(dialect DialectInterface, err error) bool {
+	var notSent *notSentError
+	if errors.As(err, &notSent) || errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	return dialect != nil && dialect.IsNotAppliedError(err)
+}
+
+// IsConnectionError returns true if the given error means that the connection to the database
+// has been lost, so the connection has to be reopened before the operation is repeated.
+func IsConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	message := strings.ToLower(err.Error())
+	for _, transient := range transientMessages {
+		if strings.Contains(message, transient) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRetryPolicyGetDelay verifies that the delay is doubled for each attempt up to the max delay
+// and the jitter subtracts at most a half of the delay.
+func TestRetryPolicyGetDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for range 100 {
+		for attempt, expected := range map[int]time.Duration{1: 100, 2: 200, 3: 400, 4: 800, 5: 1000, 50: 1000} {
+			delay := policy.GetDelay(attempt)
+			assert.LessOrEqual(t, delay, expected*time.Millisecond)
+			assert.GreaterOrEqual(t, delay, expected*time.Millisecond/2)
+		}
+	}
+	policy = RetryPolicy{}
+	assert.LessOrEqual(t, policy.GetDelay(1), RETRY_BASE_DELAY)
+	assert.LessOrEqual(t, policy.GetDelay(100), RETRY_MAX_DELAY)
+	assert.GreaterOrEqual(t, policy.GetDelay(100), RETRY_MAX_DELAY/2)
+}
+
+// TestRetryPolicyDo verifies that only transient errors are retried, up to the max number of attempts,
+// and that each retry is reported with OnRetry.
+func TestRetryPolicyDo(t *testing.T) {
+	var retries []int
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		OnRetry:     func(attempt int, delay time.Duration, err error) { retries = append(retries, attempt) },
+	}
+	attempts := 0
+	err := policy.Do(context.Background(), &DialectSqlite{}, func(attempt int) error {
+		attempts = attempt
+		if attempt < 3 {
+			return driver.ErrBadConn
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, []int{1, 2}, retries)
+
+	attempts = 0
+	err = policy.Do(context.Background(), &DialectSqlite{}, func(attempt int) error {
+		attempts = attempt
+		return driver.ErrBadConn
+	})
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = policy.Do(context.Background(), &DialectSqlite{}, func(attempt int) error {
+		attempts = attempt
+		return errors.New("syntax error")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	attempts = 0
+	policy.BaseDelay = time.Hour
+	err = policy.Do(ctx, &DialectSqlite{}, func(attempt int) error {
+		attempts = attempt
+		return driver.ErrBadConn
+	})
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	assert.Equal(t, 1, attempts)
+}
+
+// TestRetryPolicyDoWrite verifies that a write is retried after the errors raised before it is sent
+// and the errors with which the server rejects it, but not after a network error while it is sent.
+func TestRetryPolicyDoWrite(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	dialect := &DialectClickHouse{}
+	tests := []struct {
+		err      error
+		attempts int
+	}{
+		{NotSent(fmt.Errorf("error preparing batch: %w", errors.New("read: connection reset by peer"))), 3},
+		{&clickhouse.Exception{Code: 252, Message: "Too many parts"}, 3},
+		{fmt.Errorf("error writing to database: %w", &clickhouse.Exception{Code: 210}), 1},
+		{fmt.Errorf("error writing to database: %w", errors.New("write: broken pipe")), 1},
+	}
+	for _, test := range tests {
+		attempts := 0
+		err := policy.DoWrite(context.Background(), dialect, func(attempt int) error {
+			attempts = attempt
+			return test.err
+		})
+		assert.Error(t, err)
+		assert.Equal(t, test.attempts, attempts, test.err.Error())
+	}
+}
+
+// TestIsTransientError verifies that lost connections are transient for all databases,
+// while cancellations and timeouts are never transient.
+func TestIsTransientError(t *testing.T) {
+	assert.False(t, IsTransientError(nil, nil))
+	assert.True(t, IsTransientError(nil, fmt.Errorf("error writing to database: %w", driver.ErrBadConn)))
+	assert.True(t, IsTransientError(&DialectPostgres{}, errors.New("read tcp 127.0.0.1:5432: connection reset by peer")))
+	assert.True(t, IsConnectionError(errors.New("write: broken pipe")))
+	assert.False(t, IsConnectionError(errors.New("table not found")))
+	assert.False(t, IsTransientError(nil, context.Canceled))
+	assert.False(t, IsTransientError(nil, fmt.Errorf("%w: %w", ErrTimeout, driver.ErrBadConn)))
+	assert.False(t, IsTransientError(nil, errors.New("syntax error")))
+}
+
+// TestCanRetryWrite verifies that a write outside a transaction is retried only if the error proves
+// that the statement has not been applied, while a read is retried after any transient error.
+func TestCanRetryWrite(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3}
+	dialect := &DialectMySql{}
+	lost := errors.New("read tcp 127.0.0.1:3306: connection reset by peer")
+	assert.True(t, policy.CanRetry(1, dialect, lost))
+	assert.False(t, policy.CanRetryWrite(1, dialect, lost))
+	assert.True(t, policy.CanRetryWrite(1, dialect, NotSent(fmt.Errorf("error reconnecting to the database: %w", lost))))
+	assert.True(t, policy.CanRetryWrite(1, dialect, fmt.Errorf("error writing to database: %w", driver.ErrBadConn)))
+	assert.False(t, policy.CanRetryWrite(3, dialect, driver.ErrBadConn))
+	assert.False(t, policy.CanRetryWrite(1, dialect, NotSent(errors.New("syntax error"))))
+	assert.Nil(t, NotSent(nil))
+	assert.Equal(t, lost.Error(), NotSent(lost).Error())
+}
+
+// lockSqliteDb locks the SQLite database with the given file name by an exclusive transaction
+// on another connection, so the queries of other connections fail with "database is locked".
+// It returns the function that releases the lock.
+func lockSqliteDb(t *testing.T, dsn string) func() {
+	locker := AppDb{Driver: DRIVER_SQLITE, Dsn: dsn}
+	assert.Nil(t, locker.Open())
+	conn, err := locker.db.Conn(context.Background())
+	assert.Nil(t, err)
+	_, err = conn.ExecContext(context.Background(), "BEGIN EXCLUSIVE")
+	assert.Nil(t, err)
+	return func() {
+		conn.ExecContext(context.Background(), "COMMIT")
+		conn.Close()
+		locker.Close()
+	}
+}
+
+// TestDataReaderRetryLocked verifies that the query failed because the database is locked
+// is executed again after the lock is released.
+func TestDataReaderRetryLocked(t *testing.T) {
+	dsn := prepareSqlitePartitionDb(t)
+	unlock := lockSqliteDb(t, dsn)
+	var retryErr error
+	dr := DataReader{
+		AppDb:     &AppDb{Driver: DRIVER_SQLITE, Dsn: dsn},
+		Query:     "SELECT id FROM test WHERE id > {{id}} ORDER BY id LIMIT 30",
+		QueryType: QUERY_TYPE_ORDERBYID,
+		Retry: RetryPolicy{
+			MaxAttempts: 3,
+			BaseDelay:   time.Millisecond,
+			OnRetry: func(attempt int, delay time.Duration, err error) {
+				retryErr = err
+				unlock()
+			},
+		},
+	}
+	ids := readPartitionIds(t, []*DataReader{&dr})[0]
+	assert.True(t, (&DialectSqlite{}).IsTransientError(retryErr), retryErr)
+	assert.Len(t, ids, 100)
+}
+
+// TestDataReaderRerun verifies that the query executed again after a failure continues
+// from the row after the last read row without duplicates for all query types.
+func TestDataReaderRerun(t *testing.T) {
+	dsn := prepareSqlitePartitionDb(t)
+	readers := map[string]*DataReader{
+		QUERY_TYPE_ORDERBYID: {
+			Query:     "SELECT id FROM test WHERE id > {{id}} ORDER BY id LIMIT 30",
+			QueryType: QUERY_TYPE_ORDERBYID,
+		},
+		QUERY_TYPE_LIMIT_OFFSET: {
+			Query:     "SELECT id FROM test ORDER BY id",
+			QueryType: QUERY_TYPE_LIMIT_OFFSET,
+			Limit:     30,
+		},
+		QUERY_TYPE_SIMPLE: {
+			Query:     "SELECT id FROM test ORDER BY id",
+			QueryType: QUERY_TYPE_SIMPLE,
+		},
+	}
+	for queryType, dr := range readers {
+		dr.AppDb = &AppDb{Driver: DRIVER_SQLITE, Dsn: dsn}
+		assert.Nil(t, dr.Open())
+		var ids []int64
+		for {
+			// The query is executed again in the middle of each query
+			if len(ids)%30 == 10 {
+				assert.Nil(t, dr.rerun(context.Background()), queryType)
+			}
+			ok, err := dr.Next(context.Background())
+			assert.Nil(t, err, queryType)
+			if !ok {
+				break
+			}
+			values, err := dr.Scan()
+			assert.Nil(t, err)
+			ids = append(ids, dr.AnyToInt64(values[0]))
+		}
+		dr.Close()
+		assert.Len(t, ids, 100, queryType)
+		for i, id := range ids {
+			assert.Equal(t, int64(i+11), id, queryType)
+		}
+	}
+}
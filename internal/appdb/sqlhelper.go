@@ -40,3 +40,15 @@ func (sqlHelper *SqlHelper) GetFromTableName() string {
 	}
 	return ""
 }
+
+// SplitTableName splits the table name qualified with the database or schema name, like "db.table",
+// into the database name and the table name without the identifier quotes.
+// The database name is empty if the table name is not qualified.
+func SplitTableName(table string) (string, string) {
+	unquoted := strings.NewReplacer("`", "", `"`, "").Replace(table)
+	schema, name, found := strings.Cut(unquoted, ".")
+	if !found {
+		return "", schema
+	}
+	return schema, name
+}
@@ -59,3 +59,14 @@ func TestStringify(t *testing.T) {
 	actual := helper.Sql
 	assert.Equal(t, expected, actual)
 }
+
+// TestSplitTableName verifies that SplitTableName splits the qualified table name into
+// the database name and the table name without the identifier quotes.
+func TestSplitTableName(t *testing.T) {
+	schema, name := SplitTableName("`db`.`table`")
+	assert.Equal(t, []string{"db", "table"}, []string{schema, name})
+	schema, name = SplitTableName("table")
+	assert.Equal(t, []string{"", "table"}, []string{schema, name})
+	dialect := DialectMySql{}
+	assert.Equal(t, "SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = 'db' AND TABLE_NAME = 'table'", dialect.EstimateRows("db.table"))
+}
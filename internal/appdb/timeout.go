@@ -0,0 +1,79 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTimeout is the cause of the contexts cancelled by a timeout.
+// The errors of the operations cancelled by a timeout wrap it, so they can be told from other errors.
+var ErrTimeout = errors.New("timeout exceeded")
+
+// WithTimeout returns a context that is cancelled with the cause ErrTimeout after the given timeout.
+// If the timeout is 0, the returned context is cancelled only with the given context.
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, ErrTimeout)
+}
+
+// TimeoutError returns the given error wrapped with ErrTimeout if the given context has been cancelled
+// by a timeout, otherwise it returns the error unchanged.
+func TimeoutError(ctx context.Context, err error) error {
+	if err == nil || errors.Is(err, ErrTimeout) || !errors.Is(context.Cause(ctx), ErrTimeout) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrTimeout, err)
+}
+
+// timeoutContext is a context that is cancelled with the cause ErrTimeout when the database
+// does not respond within the timeout. The timer is started before each wait for the database
+// and stopped after it, so the time spent between the waits, for example writing the read rows,
+// is not counted.
+type timeoutContext struct {
+	// Context passed to the database operations.
+	ctx context.Context
+	// cancel cancels the context with the given cause.
+	cancel context.CancelCauseFunc
+	// Max time of one wait for the database. If 0, the waits are not limited.
+	timeout time.Duration
+	// timer cancels the context when the timeout expires.
+	timer *time.Timer
+}
+
+// newTimeoutContext returns a timeoutContext derived from the given context with the given timeout.
+func newTimeoutContext(ctx context.Context, timeout time.Duration) *timeoutContext {
+	timeoutCtx, cancel := context.WithCancelCause(ctx)
+	return &timeoutContext{ctx: timeoutCtx, cancel: cancel, timeout: timeout}
+}
+
+// start starts the timer of one wait for the database.
+func (tc *timeoutContext) start() {
+	if tc.timeout <= 0 {
+		return
+	}
+	if tc.timer == nil {
+		tc.timer = time.AfterFunc(tc.timeout, func() { tc.cancel(ErrTimeout) })
+		return
+	}
+	tc.timer.Reset(tc.timeout)
+}
+
+// stop stops the timer after the database has responded.
+func (tc *timeoutContext) stop() {
+	if tc.timer != nil {
+		tc.timer.Stop()
+	}
+}
+
+// close stops the timer and cancels the context, it is called when the context is not used anymore.
+func (tc *timeoutContext) close() {
+	tc.stop()
+	tc.cancel(context.Canceled)
+}
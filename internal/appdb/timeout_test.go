@@ -0,0 +1,80 @@
+// Description: This package provides db management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appdb
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// SLOW_QUERY is a SQLite query that runs for several seconds.
+const SLOW_QUERY = "WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 1000000000) SELECT COUNT(*) FROM c"
+
+// TestTimeoutError verifies that only the errors of the contexts cancelled by a timeout are wrapped with ErrTimeout.
+func TestTimeoutError(t *testing.T) {
+	err := errors.New("error")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NotErrorIs(t, TimeoutError(ctx, err), ErrTimeout)
+
+	ctx, cancel = WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	assert.ErrorIs(t, TimeoutError(ctx, err), ErrTimeout)
+	assert.ErrorIs(t, TimeoutError(ctx, err), err)
+	assert.Nil(t, TimeoutError(ctx, nil))
+
+	ctx, cancel = WithTimeout(context.Background(), 0)
+	defer cancel()
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+}
+
+// TestTimeoutContext verifies that the timeout context counts only the time between start and stop.
+func TestTimeoutContext(t *testing.T) {
+	tc := newTimeoutContext(context.Background(), 50*time.Millisecond)
+	defer tc.close()
+	for range 3 {
+		tc.start()
+		time.Sleep(20 * time.Millisecond)
+		tc.stop()
+		time.Sleep(40 * time.Millisecond)
+	}
+	assert.NoError(t, tc.ctx.Err())
+	tc.start()
+	<-tc.ctx.Done()
+	assert.ErrorIs(t, context.Cause(tc.ctx), ErrTimeout)
+}
+
+// TestDataReaderQueryTimeout verifies that a query that exceeds the query timeout fails with ErrTimeout.
+func TestDataReaderQueryTimeout(t *testing.T) {
+	dr := DataReader{
+		AppDb:        &AppDb{Driver: DRIVER_SQLITE, Dsn: filepath.Join(t.TempDir(), "timeout.db")},
+		Query:        SLOW_QUERY,
+		QueryType:    QUERY_TYPE_SIMPLE,
+		QueryTimeout: 100 * time.Millisecond,
+	}
+	assert.NoError(t, dr.Open())
+	defer dr.Close()
+	start := time.Now()
+	_, err := dr.Next(context.Background())
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// TestAppDbExecTimeout verifies that a statement that exceeds the timeout of the context fails with ErrTimeout.
+func TestAppDbExecTimeout(t *testing.T) {
+	db := AppDb{Driver: DRIVER_SQLITE, Dsn: filepath.Join(t.TempDir(), "timeout.db"), KillQueryOnTimeout: true}
+	assert.NoError(t, db.Open())
+	defer db.Close()
+	ctx, cancel := WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := db.GetScalarContext(ctx, SLOW_QUERY)
+	assert.ErrorIs(t, err, ErrTimeout)
+}
@@ -0,0 +1,227 @@
+// Description: This package provides output file management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"runtime"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// Constants for compression types.
+const (
+	COMPRESSION_NONE = ""
+	COMPRESSION_GZIP = "gzip"
+	COMPRESSION_ZSTD = "zstd"
+	COMPRESSION_LZ4  = "lz4"
+)
+
+// Part describes a completed or current part of the output split into several files.
+type Part struct {
+	// Name of the part file without the directory
+	File string `json:"file"`
+	// Number of rows written to the part
+	Rows int64 `json:"rows"`
+	// Size of the part file in bytes
+	Bytes int64 `json:"bytes"`
+	// SHA-256 checksum of the part file in hex, empty for the current part
+	Sha256 string `json:"sha256,omitempty"`
+}
+
+// AppFile represents an output file that is optionally compressed.
+// The written data is compressed with the set Compression before it is written to the file.
+// The SHA-256 checksum of the file content is calculated while writing.
+type AppFile struct {
+	// Path to the file.
+	Path string
+	// Compression type: "", "gzip", "zstd" or "lz4".
+	// See: COMPRESSION_NONE, COMPRESSION_GZIP, COMPRESSION_ZSTD, COMPRESSION_LZ4
+	Compression string
+	// Compression level. 0 means the default level of the compression type.
+	// gzip: 1-9, zstd: 1-22, lz4: 1-9.
+	Level int
+	// Opened file.
+	file *os.File
+	// Compressing writer of the file, nil if the file is not compressed.
+	compressor io.WriteCloser
+	// Writer of the file content that also calculates the checksum.
+	output io.Writer
+	// SHA-256 hash of the file content.
+	hash hash.Hash
+}
+
+// GetExtension returns the file extension of the given compression type, for example ".gz" for gzip.
+// It returns an empty string if the file is not compressed.
+func GetExtension(compression string) string {
+	switch compression {
+	case COMPRESSION_GZIP:
+		return ".gz"
+	case COMPRESSION_ZSTD:
+		return ".zst"
+	case COMPRESSION_LZ4:
+		return ".lz4"
+	default:
+		return ""
+	}
+}
+
+// Create creates the file specified by the Path field and the compressing writer.
+// It returns an error if the file cannot be created or the compression type or level is not valid.
+func (af *AppFile) Create() error {
+	file, err := os.Create(af.Path)
+	if err != nil {
+		return err
+	}
+	af.file = file
+	af.hash = sha256.New()
+	af.output = io.MultiWriter(file, af.hash)
+	af.compressor, err = af.createCompressor(af.output)
+	if err != nil {
+		af.file.Close()
+		af.file = nil
+		return err
+	}
+	return nil
+}
+
+// Resume opens the existing file specified by the Path field for appending.
+// The file is truncated to the given size to remove the data written after the last saved position.
+// The checksum calculation starts with the content of the truncated file.
+// Compressed files cannot be resumed, as the compressed stream cannot be continued from an arbitrary position.
+// It returns an error if the file cannot be opened or is compressed.
+func (af *AppFile) Resume(size int64) error {
+	if af.Compression != COMPRESSION_NONE {
+		return fmt.Errorf("compressed file cannot be resumed: %s", af.Path)
+	}
+	file, err := os.OpenFile(af.Path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	af.hash = sha256.New()
+	err = file.Truncate(size)
+	if err == nil {
+		_, err = io.Copy(af.hash, io.NewSectionReader(file, 0, size))
+	}
+	if err == nil {
+		_, err = file.Seek(size, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return err
+	}
+	af.file = file
+	af.output = io.MultiWriter(file, af.hash)
+	return nil
+}
+
+// createCompressor creates the compressing writer of the set Compression type for the given file writer.
+// It returns nil if the file is not compressed.
+func (af *AppFile) createCompressor(file io.Writer) (io.WriteCloser, error) {
+	switch af.Compression {
+	case COMPRESSION_NONE:
+		return nil, nil
+	case COMPRESSION_GZIP:
+		level := gzip.DefaultCompression
+		if af.Level != 0 {
+			level = af.Level
+		}
+		return gzip.NewWriterLevel(file, level)
+	case COMPRESSION_ZSTD:
+		options := []zstd.EOption{zstd.WithEncoderConcurrency(runtime.GOMAXPROCS(0))}
+		if af.Level != 0 {
+			options = append(options, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(af.Level)))
+		}
+		return zstd.NewWriter(file, options...)
+	case COMPRESSION_LZ4:
+		writer := lz4.NewWriter(file)
+		options := []lz4.Option{lz4.ConcurrencyOption(-1)}
+		if af.Level != 0 {
+			if af.Level < 1 || af.Level > 9 {
+				return nil, fmt.Errorf("invalid lz4 compression level: %d", af.Level)
+			}
+			options = append(options, lz4.CompressionLevelOption(lz4.CompressionLevel(1<<(8+af.Level))))
+		}
+		if err := writer.Apply(options...); err != nil {
+			return nil, err
+		}
+		return writer, nil
+	default:
+		return nil, fmt.Errorf("unknown compression: %s", af.Compression)
+	}
+}
+
+// Write writes the given data to the file, compressing it if the compression is set.
+// It returns an error if the file is not opened.
+func (af *AppFile) Write(data []byte) (int, error) {
+	if af.file == nil {
+		return 0, fmt.Errorf("file is not opened")
+	}
+	if af.compressor != nil {
+		return af.compressor.Write(data)
+	}
+	return af.output.Write(data)
+}
+
+// Flush writes the data buffered by the compressing writer to the file, so the size of the file on disk
+// includes all written data. It does nothing if the file is not compressed.
+// Each flush completes a block of the compressed stream, so frequent flushes reduce the compression ratio.
+func (af *AppFile) Flush() error {
+	if af.file == nil {
+		return fmt.Errorf("file is not opened")
+	}
+	if flusher, ok := af.compressor.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// Name returns the path of the file.
+func (af *AppFile) Name() string {
+	return af.Path
+}
+
+// Size returns the current size of the file on disk. It can be called after Close.
+// The size of an open compressed file does not include the data buffered by the compressing writer until Flush.
+func (af *AppFile) Size() (int64, error) {
+	info, err := os.Stat(af.Path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Sha256 returns the SHA-256 checksum in hex of the content written to the file so far.
+// The checksum of a compressed file is complete after Close.
+func (af *AppFile) Sha256() string {
+	if af.hash == nil {
+		return ""
+	}
+	return hex.EncodeToString(af.hash.Sum(nil))
+}
+
+// Close writes the buffered compressed data and closes the file.
+// It can be called several times, the next calls do nothing.
+func (af *AppFile) Close() error {
+	if af.file == nil {
+		return nil
+	}
+	var err error
+	if af.compressor != nil {
+		err = af.compressor.Close()
+		af.compressor = nil
+	}
+	if closeErr := af.file.Close(); err == nil {
+		err = closeErr
+	}
+	af.file = nil
+	return err
+}
@@ -0,0 +1,140 @@
+// Description: This package provides output file management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// Constants for testing.
+const (
+	TEST_DATA = "INSERT INTO table VALUES (1), (2), (3);\n"
+)
+
+// writeTestFile creates a file with the given compression and level in a temporary directory,
+// writes the test data to it and closes it. It returns the path of the file.
+func writeTestFile(t *testing.T, compression string, level int) string {
+	file := AppFile{
+		Path:        filepath.Join(t.TempDir(), "test.sql"+GetExtension(compression)),
+		Compression: compression,
+		Level:       level,
+	}
+	err := file.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = file.Write([]byte(TEST_DATA))
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+	assert.Nil(t, file.Close())
+	return file.Path
+}
+
+// TestCompression verifies that the data written with each compression type and level can be decompressed.
+func TestCompression(t *testing.T) {
+	readers := map[string]func(r io.Reader) (io.Reader, error){
+		COMPRESSION_GZIP: func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		COMPRESSION_ZSTD: func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) },
+		COMPRESSION_LZ4:  func(r io.Reader) (io.Reader, error) { return lz4.NewReader(r), nil },
+	}
+	for compression, newReader := range readers {
+		for _, level := range []int{0, 1, 9} {
+			path := writeTestFile(t, compression, level)
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader, err := newReader(file)
+			assert.Nil(t, err)
+			data, err := io.ReadAll(reader)
+			assert.Nil(t, err)
+			assert.Equal(t, TEST_DATA, string(data), compression)
+			file.Close()
+		}
+	}
+}
+
+// TestFlush verifies that after Flush the compressed data written so far is in the file on disk
+// and can be decompressed before the file is closed.
+func TestFlush(t *testing.T) {
+	readers := map[string]func(r io.Reader) (io.Reader, error){
+		COMPRESSION_NONE: func(r io.Reader) (io.Reader, error) { return r, nil },
+		COMPRESSION_GZIP: func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		COMPRESSION_ZSTD: func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) },
+		COMPRESSION_LZ4:  func(r io.Reader) (io.Reader, error) { return lz4.NewReader(r), nil },
+	}
+	for compression, newReader := range readers {
+		file := AppFile{Path: filepath.Join(t.TempDir(), "test.sql"+GetExtension(compression)), Compression: compression}
+		assert.NotNil(t, file.Flush())
+		if err := file.Create(); err != nil {
+			t.Fatal(err)
+		}
+		_, err := file.Write([]byte(TEST_DATA))
+		assert.Nil(t, err)
+		assert.Nil(t, file.Flush(), compression)
+		size, err := file.Size()
+		assert.Nil(t, err)
+		assert.Greater(t, size, int64(0), compression)
+
+		saved, err := os.Open(file.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader, err := newReader(saved)
+		assert.Nil(t, err)
+		data := make([]byte, len(TEST_DATA))
+		_, err = io.ReadFull(reader, data)
+		assert.Nil(t, err, compression)
+		assert.Equal(t, TEST_DATA, string(data), compression)
+		saved.Close()
+		assert.Nil(t, file.Close())
+	}
+}
+
+// TestCreateUnknownCompression verifies that Create returns an error for an unknown compression type.
+func TestCreateUnknownCompression(t *testing.T) {
+	file := AppFile{Path: filepath.Join(t.TempDir(), "test.sql"), Compression: "zip"}
+	assert.NotNil(t, file.Create())
+}
+
+// TestGetExtension verifies the file extensions of the compression types.
+func TestGetExtension(t *testing.T) {
+	assert.Equal(t, "", GetExtension(COMPRESSION_NONE))
+	assert.Equal(t, ".gz", GetExtension(COMPRESSION_GZIP))
+	assert.Equal(t, ".zst", GetExtension(COMPRESSION_ZSTD))
+	assert.Equal(t, ".lz4", GetExtension(COMPRESSION_LZ4))
+}
+
+// TestResume verifies that an uncompressed file is truncated to the saved size and appended,
+// the checksum includes the resumed content and that a compressed file cannot be resumed.
+func TestResume(t *testing.T) {
+	path := writeTestFile(t, COMPRESSION_NONE, 0)
+	file := AppFile{Path: path}
+	err := file.Resume(6)
+	assert.Nil(t, err)
+	_, err = file.Write([]byte(" IGNORE"))
+	assert.Nil(t, err)
+	size, err := file.Size()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(13), size)
+	assert.Nil(t, file.Close())
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "INSERT IGNORE", string(data))
+	checksum := sha256.Sum256(data)
+	assert.Equal(t, hex.EncodeToString(checksum[:]), file.Sha256())
+
+	compressed := AppFile{Path: path, Compression: COMPRESSION_GZIP}
+	assert.NotNil(t, compressed.Resume(0))
+}
@@ -5,15 +5,27 @@ package appfilepath
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
 )
 
+// Constants for date and time formatting and template placeholders.
+const (
+	DATE_FORMAT        = "20060102"
+	DATETIME_FORMAT    = "20060102_150405"
+	TEMPLATE_DATE      = "{{date}}"
+	TEMPLATE_DATETIME  = "{{datetime}}"
+	INVALID_CHARACTERS = `/\:*?"<>|`
+)
+
 // AppFilePath represents a file path with additional metadata and utility methods for file path manipulation.
 type AppFilePath struct {
 	// Path to the file.
 	Path string
+	// Date and time inserted into the file name. If zero, the current date and time is used.
+	Time time.Time
 }
 
 // Returns the file path with the current date and time inserted
@@ -24,7 +36,74 @@ func (fp *AppFilePath) GetWithDateTime() string {
 	dir, fileName := filepath.Split(fp.Path)
 	ext := filepath.Ext(fileName)
 	name := strings.TrimSuffix(fileName, ext)
-	currentTime := time.Now().Format("20060102_150405")
-	newFileName := fmt.Sprintf("%s_%s%s", name, currentTime, ext)
+	newFileName := fmt.Sprintf("%s_%s%s", name, fp.GetDateTime(), ext)
 	return filepath.Join(dir, newFileName)
 }
+
+// GetFromTemplate returns the file path with the placeholders of the Path field replaced.
+// The {{date}} and {{datetime}} placeholders are replaced with the date and time formatted as
+// "YYYYMMDD" and "YYYYMMDD_HHMMSS". Other placeholders are replaced with the given values by name,
+// for example the value with the key "table" replaces {{table}}. The characters that are not
+// allowed in file names are replaced with "_" in the values.
+func (fp *AppFilePath) GetFromTemplate(values map[string]string) string {
+	replacements := []string{TEMPLATE_DATE, fp.GetDate(), TEMPLATE_DATETIME, fp.GetDateTime()}
+	for name, value := range values {
+		replacements = append(replacements, "{{"+name+"}}", ReplaceInvalidCharacters(value))
+	}
+	return strings.NewReplacer(replacements...).Replace(fp.Path)
+}
+
+// GetDate returns the date of the Time field formatted as "YYYYMMDD".
+func (fp *AppFilePath) GetDate() string {
+	return fp.getTime().Format(DATE_FORMAT)
+}
+
+// GetDateTime returns the date and time of the Time field formatted as "YYYYMMDD_HHMMSS".
+func (fp *AppFilePath) GetDateTime() string {
+	return fp.getTime().Format(DATETIME_FORMAT)
+}
+
+// getTime returns the Time field or the current date and time if it is not set.
+func (fp *AppFilePath) getTime() time.Time {
+	if fp.Time.IsZero() {
+		return time.Now()
+	}
+	return fp.Time
+}
+
+// ReplaceInvalidCharacters replaces the characters that are not allowed in file names,
+// like path separators, with "_".
+func ReplaceInvalidCharacters(name string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(INVALID_CHARACTERS, r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// Exists returns true if any of the files named by the Path field followed by
+// one of the given suffixes exists, for example the Path "out/table" and the suffix ".sql".
+func (fp *AppFilePath) Exists(suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if _, err := os.Stat(fp.Path + suffix); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAvailable returns the Path field if none of the files with the given suffixes exists.
+// Otherwise, it returns the Path field with the first number suffix "_1", "_2", ... for which
+// none of the files exists, for example "out/table_1".
+func (fp *AppFilePath) GetAvailable(suffixes []string) string {
+	if !fp.Exists(suffixes) {
+		return fp.Path
+	}
+	for i := 1; ; i++ {
+		candidate := AppFilePath{Path: fmt.Sprintf("%s_%d", fp.Path, i)}
+		if !candidate.Exists(suffixes) {
+			return candidate.Path
+		}
+	}
+}
@@ -5,7 +5,10 @@ package appfilepath
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -29,3 +32,31 @@ func TestGetWithDateTimePath(t *testing.T) {
 	fmt.Println(actual)
 	assert.Regexp(t, `^\Svar\Slog\Stest_\d{8}_\d{6}\.txt$`, actual)
 }
+
+// Tests the GetFromTemplate method of the AppFilePath type.
+// It tests that the date, date and time and the given values are inserted into the path
+// and the characters that are not allowed in file names are replaced in the values.
+func TestGetFromTemplate(t *testing.T) {
+	fp := AppFilePath{
+		Path: "out/{{table}}_{{dataset_index}}_{{date}}_{{datetime}}_{{unknown}}",
+		Time: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	actual := fp.GetFromTemplate(map[string]string{"table": "db/table", "dataset_index": "1"})
+	assert.Equal(t, "out/db_table_1_20250102_20250102_030405_{{unknown}}", actual)
+}
+
+// Tests the GetAvailable method of the AppFilePath type.
+// It tests that the path is returned if no file exists and the first number suffix
+// for which none of the files with the given suffixes exists otherwise.
+func TestGetAvailable(t *testing.T) {
+	dir := t.TempDir()
+	fp := AppFilePath{Path: filepath.Join(dir, "table")}
+	suffixes := []string{".0001.csv", ".manifest.json"}
+	assert.False(t, fp.Exists(suffixes))
+	assert.Equal(t, fp.Path, fp.GetAvailable(suffixes))
+
+	assert.NoError(t, os.WriteFile(fp.Path+".manifest.json", []byte("{}"), 0644))
+	assert.NoError(t, os.WriteFile(fp.Path+"_1.0001.csv", []byte(""), 0644))
+	assert.True(t, fp.Exists(suffixes))
+	assert.Equal(t, fp.Path+"_2", fp.GetAvailable(suffixes))
+}
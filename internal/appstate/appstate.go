@@ -0,0 +1,119 @@
+// Description: This package provides state management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appstate
+
+import (
+	"copysqldatatool/internal/appdb"
+	"copysqldatatool/internal/appfile"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// Constants for date and time formatting.
+const (
+	REFERENCE_DATE = "2006-01-02 15:04:05"
+)
+
+// DatasetState represents the saved progress of a single dataset.
+type DatasetState struct {
+	// Table name of the dataset
+	Table string `json:"table"`
+	// Position of the data reader after the last committed batch
+	Reader appdb.ReaderState `json:"reader"`
+	// Number of rows committed
+	Rows int64 `json:"rows"`
+	// Path of the output files without the extension, reused when the dataset is resumed
+	FileName string `json:"file_name,omitempty"`
+	// Size of the output file after the last committed batch
+	FileSize int64 `json:"file_size,omitempty"`
+	// Parts of the output split into several files after the last committed batch
+	FileParts []appfile.Part `json:"file_parts,omitempty"`
+	// Number of partitions the dataset is split into, 0 if the dataset is not split
+	Partitions int `json:"partitions,omitempty"`
+	// True if the dataset has been processed completely
+	Completed bool `json:"completed"`
+	// Date and time of the last update in the format "YYYY-MM-DD HH:MM:SS"
+	UpdatedAt string `json:"updated_at"`
+}
+
+// AppState represents the state file that stores the progress of all datasets.
+// It is safe for concurrent use by multiple goroutines.
+type AppState struct {
+	// Path to the state file.
+	Path string `json:"-"`
+	// Saved datasets progress by dataset key.
+	Datasets map[string]DatasetState `json:"datasets"`
+	// mutex is used to synchronize access to the datasets and the state file.
+	mutex sync.Mutex
+}
+
+// Load reads the state file specified by the Path field.
+// If the state file does not exist, it starts with an empty state and returns no error.
+// It returns an error if the file cannot be read or parsed.
+func (state *AppState) Load() error {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	state.Datasets = make(map[string]DatasetState)
+	data, err := os.ReadFile(state.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		return err
+	}
+	if state.Datasets == nil {
+		state.Datasets = make(map[string]DatasetState)
+	}
+	return nil
+}
+
+// Get returns the saved state of the dataset with the given key.
+// The second return value is false if there is no saved state for the dataset.
+func (state *AppState) Get(key string) (DatasetState, bool) {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	datasetState, ok := state.Datasets[key]
+	return datasetState, ok
+}
+
+// Set stores the state of the dataset with the given key and saves the state file.
+// It returns an error if the state file cannot be written.
+func (state *AppState) Set(key string, datasetState DatasetState) error {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	if state.Datasets == nil {
+		state.Datasets = make(map[string]DatasetState)
+	}
+	datasetState.UpdatedAt = time.Now().Format(REFERENCE_DATE)
+	state.Datasets[key] = datasetState
+	return state.save()
+}
+
+// save writes the state to a temporary file and renames it to the state file,
+// so the state file is never left partially written. The caller must hold the mutex.
+func (state *AppState) save() error {
+	if state.Path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state, "", "    ")
+	if err != nil {
+		return err
+	}
+	tmpPath := state.Path + ".tmp"
+	err = os.WriteFile(tmpPath, data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, state.Path)
+}
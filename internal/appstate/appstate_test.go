@@ -0,0 +1,49 @@
+// Description: This package provides state management for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package appstate
+
+import (
+	"copysqldatatool/internal/appdb"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadNotExists verifies that loading a state file that does not exist
+// starts with an empty state and does not return an error.
+func TestLoadNotExists(t *testing.T) {
+	state := AppState{Path: filepath.Join(t.TempDir(), "state.json")}
+	err := state.Load()
+	assert.Nil(t, err)
+	_, ok := state.Get("0:table")
+	assert.False(t, ok)
+}
+
+// TestSetAndLoad verifies that the saved dataset state is written to the state file
+// and can be loaded back by a new AppState instance.
+func TestSetAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	state := AppState{Path: path}
+	expected := DatasetState{
+		Table: "table",
+		Reader: appdb.ReaderState{
+			QueryType: appdb.QUERY_TYPE_ORDERBYID,
+			LastId:    100,
+		},
+		Rows: 100,
+	}
+	err := state.Set("0:table", expected)
+	assert.Nil(t, err)
+
+	loaded := AppState{Path: path}
+	err = loaded.Load()
+	assert.Nil(t, err)
+	actual, ok := loaded.Get("0:table")
+	assert.True(t, ok)
+	assert.Equal(t, expected.Reader, actual.Reader)
+	assert.Equal(t, expected.Rows, actual.Rows)
+	assert.False(t, actual.Completed)
+	assert.NotEmpty(t, actual.UpdatedAt)
+}
@@ -4,15 +4,27 @@
 package main
 
 import (
+	"context"
 	"copysqldatatool/internal/app"
 	"copysqldatatool/internal/appconfig"
 	"copysqldatatool/internal/appdb"
+	"copysqldatatool/internal/appfile"
 	"copysqldatatool/internal/appfilepath"
 	"copysqldatatool/internal/applog"
+	"copysqldatatool/internal/appstate"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
 	"sync"
+	"syscall"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 )
@@ -20,7 +32,18 @@ import (
 // Constants.
 const (
 	// Constants for log messages.
-	ERROR = "Error:"
+	ERROR   = "Error:"
+	TIMEOUT = "Timeout:"
+	// Number of batches written in one transaction to SQLite destinations.
+	// SQLite syncs the database file on each commit, so grouping batches speeds up writing.
+	SQLITE_BATCHES_PER_TRANSACTION = 100
+	// Suffix of the file of the rows rejected by the destination database.
+	REJECTED_FILE_SUFFIX = ".rejected.jsonl"
+	// Bytes of max_allowed_packet reserved for the packet header and the protocol overhead of the statement.
+	MAX_PACKET_RESERVE = 1024
+	// Names of the connections limited by the worker pool.
+	POOL_CONNECTION_SOURCE = "source"
+	POOL_CONNECTION_DEST   = "dest"
 )
 
 var (
@@ -30,6 +53,12 @@ var (
 	Config appconfig.Config
 	// Application log.
 	Log applog.AppLog
+	// Application state with the progress of the datasets.
+	State *appstate.AppState
+	// Start date and time of the run, used in the output file names.
+	RunTime time.Time
+	// Unique id of the run, used in the output file names.
+	RunId string
 )
 
 // Main is the main entry point of the application.
@@ -46,6 +75,9 @@ func main() {
 	configFileName := flag.String("config", "config.json", "Path to the configuration file")
 	logFileName := flag.String("log", "", "Path to the log file")
 	goroutines := flag.Bool("go", false, "Use goroutines")
+	concurrency := flag.Int("concurrency", 0, "Max number of datasets processed at the same time")
+	stateFileName := flag.String("state", "state.json", "Path to the state file")
+	resume := flag.Bool("resume", false, "Resume datasets from the last saved position")
 	flag.Parse()
 
 	if *version {
@@ -59,7 +91,11 @@ func main() {
 		defer logFile.Close()
 	}
 
+	RunTime = time.Now()
+	RunId = createRunId(RunTime)
+
 	Log.Info("Program started")
+	Log.Info("Run id:", RunId)
 	Log.Info("Config file:", *configFileName)
 
 	if loadConfig(*configFileName) != nil {
@@ -67,23 +103,103 @@ func main() {
 		return
 	}
 
-	if *goroutines {
-		var wg sync.WaitGroup
-		for _, dataset := range Config.Datasets {
-			wg.Add(1)
-			go func(d appconfig.Dataset) {
-				defer wg.Done()
-				processDataset(d)
-			}(dataset)
+	if loadState(*stateFileName, *resume) != nil {
+		return
+	}
+
+	ctx, cancel := createSignalContext()
+	defer cancel()
+
+	pool := createWorkerPool(*goroutines, *concurrency)
+	pool.Run(createDatasetTasks(ctx))
+
+	Log.Ok("Program ended")
+}
+
+// createSignalContext returns a context that is cancelled when the program receives SIGINT or SIGTERM.
+// The datasets being processed complete the batch being written, run the session end scripts and save
+// their position, the datasets that have not been started are skipped.
+// After the first signal the default handling is restored, so a second signal terminates the program at once.
+func createSignalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			Log.Warn("Signal received:", sig, "Cancelling datasets")
+			cancel()
+		case <-ctx.Done():
 		}
-		wg.Wait()
-	} else {
-		for _, dataset := range Config.Datasets {
-			processDataset(dataset)
+		signal.Stop(signals)
+	}()
+	return ctx, cancel
+}
+
+// createWorkerPool creates the worker pool that processes the datasets.
+// The number of workers is the given concurrency or the max concurrency of the config if it is 0.
+// Without a concurrency the datasets are processed one by one, or all at the same time if goroutines is true.
+// The connections are limited by the max connections of the source and destination databases.
+func createWorkerPool(goroutines bool, concurrency int) *app.WorkerPool {
+	if concurrency <= 0 {
+		concurrency = Config.Config.MaxConcurrency
+	}
+	if concurrency <= 0 && !goroutines {
+		concurrency = 1
+	}
+	if concurrency > 0 {
+		Log.Info("Concurrency:", concurrency)
+	}
+	return &app.WorkerPool{
+		Workers: concurrency,
+		Limits: map[string]int{
+			POOL_CONNECTION_SOURCE: Config.Config.Source.MaxConnections,
+			POOL_CONNECTION_DEST:   Config.Config.Dest.MaxConnections,
+		},
+	}
+}
+
+// createDatasetTasks creates a pool task for each dataset of the config with the priority of the dataset
+// and the number of source and destination connections it opens.
+// The parallelism of a dataset is limited by the max connections of the databases.
+func createDatasetTasks(ctx context.Context) []app.PoolTask {
+	tasks := make([]app.PoolTask, 0, len(Config.Datasets))
+	for i, dataset := range Config.Datasets {
+		dataset.Parallelism = limitParallelism(dataset.Parallelism, Config.Config.Source.MaxConnections)
+		if dataset.CopyToDbEnabled() {
+			dataset.Parallelism = limitParallelism(dataset.Parallelism, Config.Config.Dest.MaxConnections)
 		}
+		tasks = append(tasks, app.PoolTask{
+			Priority:    dataset.Priority,
+			Connections: getDatasetConnections(dataset),
+			Run:         func() { processDataset(ctx, i, dataset) },
+		})
 	}
+	return tasks
+}
 
-	Log.Ok("Program ended")
+// limitParallelism returns the given parallelism limited by the given max number of connections.
+func limitParallelism(parallelism int, maxConnections int) int {
+	if maxConnections > 0 && parallelism > maxConnections {
+		return maxConnections
+	}
+	return parallelism
+}
+
+// getDatasetConnections returns the number of source and destination connections opened by the dataset.
+// A dataset with parallelism opens a connection to each database for every partition,
+// and every partition opens a destination connection for each of its writers.
+// Disabled datasets and datasets without a table or query open no connections.
+func getDatasetConnections(dataset appconfig.Dataset) map[string]int {
+	if !dataset.Enabled || dataset.Table == "" || dataset.Query == "" {
+		return nil
+	}
+	count := max(dataset.Parallelism, 1)
+	connections := map[string]int{POOL_CONNECTION_SOURCE: count}
+	if dataset.CopyToDbEnabled() {
+		connections[POOL_CONNECTION_DEST] = count * max(dataset.Writers, 1)
+	}
+	return connections
 }
 
 // prepareLogFile prepares a log file by creating a new file with the current date and time in its name.
@@ -106,6 +222,15 @@ func prepareLogFile(logFile string) (*os.File, error) {
 	return file, nil
 }
 
+// createRunId creates a unique id of the run from the given start date and time and random bytes,
+// for example "20250102_030405_1a2b3c4d".
+func createRunId(runTime time.Time) string {
+	fp := appfilepath.AppFilePath{Time: runTime}
+	random := make([]byte, 4)
+	rand.Read(random)
+	return fp.GetDateTime() + "_" + hex.EncodeToString(random)
+}
+
 // loadConfig initializes the global Config variable by loading and validating the configuration
 // from the provided file path. It logs any errors encountered during the loading or validation
 // process. If no datasets are found in the configuration, it logs an error and returns an error.
@@ -132,13 +257,37 @@ func loadConfig(configPath string) error {
 	return nil
 }
 
+// loadState initializes the global State variable with the given state file path.
+// If resume is true, it loads the saved progress of the datasets from the state file,
+// otherwise the progress is started from scratch and the state file is overwritten.
+// Returns an error if the state file cannot be loaded.
+func loadState(statePath string, resume bool) error {
+	State = &appstate.AppState{Path: statePath}
+	if !resume {
+		return nil
+	}
+	Log.Info("Resume from state file:", statePath)
+	err := State.Load()
+	if err != nil {
+		Log.Error("Error loading state:", err)
+		return err
+	}
+	return nil
+}
+
 // processDataset processes a single dataset by first checking its enabled status,
 // table name, and query validity. If the dataset is disabled, has an empty table name,
 // or an empty query, it logs a warning or error and returns without processing.
 // If valid, it logs the start of processing, calls the process function to handle
 // the dataset, and logs the result of the processing.
-func processDataset(dataset appconfig.Dataset) {
+// The index of the dataset in the config is used to build the key of the dataset state and the output file name.
+// The dataset is skipped if the given context is done before it is started.
+func processDataset(ctx context.Context, index int, dataset appconfig.Dataset) {
 	datasetLog := createDatasetLog(dataset)
+	if ctx.Err() != nil {
+		datasetLog.Warn("Skipping cancelled table:", dataset.Table)
+		return
+	}
 	if !dataset.Enabled {
 		datasetLog.Warn("Skipping disabled table:", dataset.Table)
 		return
@@ -152,9 +301,13 @@ func processDataset(dataset appconfig.Dataset) {
 		return
 	}
 	datasetLog.Info("Processing table:", dataset.Table)
-	err := process(Config.Config.Source, Config.Config.Dest, dataset, datasetLog)
+	err := process(ctx, Config.Config.Source, Config.Config.Dest, dataset, index, datasetLog)
 	if err == nil {
 		datasetLog.Ok("Processing completed for table:", dataset.Table)
+	} else if errors.Is(err, appdb.ErrTimeout) {
+		datasetLog.Error("Timeout processing table:", dataset.Table, TIMEOUT, err)
+	} else if errors.Is(err, app.ErrCancelled) {
+		datasetLog.Warn("Processing cancelled for table:", dataset.Table)
 	} else {
 		datasetLog.Error("Error processing table:", dataset.Table, ERROR, err)
 	}
@@ -174,153 +327,771 @@ func createDatasetLog(dataset appconfig.Dataset) *applog.AppLog {
 // process handles the data processing for a given dataset by checking its configuration
 // and performing the necessary actions based on the dataset's settings. It supports
 // writing data to a file or a database, or both, depending on the dataset's CopyTo
-// configuration. The function initializes the data reader, manages file creation,
-// connects to the destination database, and executes the data processing logic, while
-// logging the progress and any errors encountered. Returns an error if any step fails.
-func process(src appconfig.DBConfig, dst appconfig.DBConfig, dataset appconfig.Dataset, log *applog.AppLog) error {
-	if dataset.CopyToFileEnabled() {
-		log.Info("Write to file started for table:", dataset.Table)
+// configuration. The source data is read once and every batch is written to all destinations.
+// The function initializes the data reader, manages file creation, connects to the destination
+// database, and executes the data processing logic, while logging the progress and any errors
+// encountered. Returns an error if any step fails.
+// The progress of the dataset is saved in the state by the index of the dataset, so an interrupted copy can be resumed.
+// When the given context is done, the processing is cancelled after the batch being written,
+// the session end script is executed and the saved position is logged.
+func process(ctx context.Context, src appconfig.DBConfig, dst appconfig.DBConfig, dataset appconfig.Dataset, index int, log *applog.AppLog) error {
+	stateKey := getStateKey(index, dataset)
+	if !dataset.CopyToFileEnabled() && !dataset.CopyToDbEnabled() {
+		log.Warn("Skipping table without destination:", dataset.Table)
+		return nil
+	}
+
+	saved, resume := getResumeState(stateKey, log)
+	if resume && saved.Completed {
+		log.Warn("Skipping completed table:", dataset.Table)
+		return nil
+	}
+
+	ctx, cancel := appdb.WithTimeout(ctx, time.Duration(dataset.DatasetTimeout)*time.Second)
+	defer cancel()
+
+	if err := checkTransactions(dst, dataset); err != nil {
+		log.Error("Error starting transactions:", err)
+		return err
+	}
+
+	if err := checkWriteMethod(dst, dataset); err != nil {
+		log.Error("Error opening writers:", err)
+		return err
+	}
+
+	if dataset.Parallelism > 1 {
+		return processPartitions(ctx, src, dst, dataset, index, stateKey, saved, resume, log)
+	}
+
+	dataReader := createDataReader(src, dataset)
+	dataReader.Retry = createRetryPolicy(dataset, log)
+	err := resumeDataReader(dataReader, saved)
+	if err != nil {
+		log.Error("Error resuming data reader:", err)
+		return err
+	}
+	err = dataReader.Open()
+	if err != nil {
+		log.Error("Error opening data reader:", err)
+		return err
+	}
+	defer dataReader.Close()
+
+	processor := createRowsProcessor(dataReader, dst, dataset, log)
 
-		file, err := createOutputFile(dataset.Table)
+	var file *appfile.AppFile
+	var split *app.SplitProcessor
+	if dataset.CopyToFileEnabled() {
+		appending := resume && !saved.Reader.IsEmpty()
+		saved.FileName, err = getOutputName(index, dataset, saved, appending)
 		if err != nil {
 			log.Error("Error creating file:", err)
 			return err
 		}
-		defer file.Close()
+		var fileProcessor app.RowsProcessorInterface
+		if dataset.SplitEnabled() {
+			split, fileProcessor, err = createSplitProcessor(dataset, saved, appending)
+			if err != nil {
+				log.Error("Error creating file:", err)
+				return err
+			}
+			defer split.Close()
+		} else {
+			file, err = openOutputFile(getOutputFileName(saved.FileName, dataset), dataset, saved.FileSize, appending)
+			if err != nil {
+				log.Error("Error creating file:", err)
+				return err
+			}
+			defer file.Close()
+			fileProcessor, err = createFileProcessor(dataset, file, appending)
+			if err != nil {
+				log.Error("Error creating file processor:", err)
+				return err
+			}
+		}
+		processor.Processors = append(processor.Processors, fileProcessor)
+	}
 
-		err = processRowsAndWriteToFile(src, file, dataset, log)
+	var dbProcessors []*app.DbProcessor
+	var dbWriters []app.RowsProcessorInterface
+	if dataset.CopyToDbEnabled() {
+		dbProcessors, err = openDbProcessors(dst, dataset, log)
+		if err != nil {
+			return err
+		}
+		for _, dbProcessor := range dbProcessors {
+			defer dbProcessor.AppDb.Close()
+			writer := createDbWriter(dbProcessor, dataset)
+			if closer, ok := writer.(io.Closer); ok {
+				defer closer.Close()
+			}
+			dbWriters = append(dbWriters, writer)
+		}
+		if dataset.Writers > 1 {
+			processor.Writers = dbWriters
+		} else {
+			processor.Processors = append(processor.Processors, dbWriters...)
+		}
+		processor.Dataset.MaxBatchBytes, err = getMaxBatchBytes(dbProcessors[0].AppDb, dataset, log)
 		if err != nil {
-			log.Error("Error processing rows to file for table:", dataset.Table, ERROR, err)
 			return err
 		}
-		log.Ok("Write to file completed for table:", dataset.Table)
+		if rejects := createRejectProcessor(index, dataset, resume && !saved.Reader.IsEmpty()); rejects != nil {
+			defer rejects.Close()
+			defer logRejected(rejects, log)
+			processor.Rejects = rejects
+		}
 	}
 
-	if dataset.CopyToDbEnabled() {
-		log.Info("Write to db started for table:", dataset.Table)
-		err := processRowsAndWriteToDb(src, dst, dataset, log)
+	processor.DataReader.OnQueryChanged.Subscribe(func(data any) {
+		log.Info("Query changed. Current query:", data)
+	})
+	subscribeStateSaving(processor, stateKey, dataset.Table, saved, file, split, log)
+
+	log.Info("Write to", dataset.CopyTo, "started for table:", dataset.Table)
+	processErr := processor.Process(ctx)
+	cancelled := errors.Is(processErr, app.ErrCancelled)
+	if cancelled {
+		logCancelledPosition(stateKey, log)
+	} else if processErr != nil {
+		log.Error("Error processing rows:", processErr)
+	}
+
+	for i, dbProcessor := range dbProcessors {
+		if processor.IsFailed(dbWriters[i]) || (processErr != nil && !cancelled && dataset.OnSinkError != appconfig.SINK_ERROR_CONTINUE) {
+			continue
+		}
+		err = closeDestinationDb(dbProcessor.AppDb, dataset, log)
 		if err != nil {
-			log.Error("Error processing rows to db for table:", dataset.Table, ERROR, err)
 			return err
 		}
-		log.Ok("Write to db completed for table:", dataset.Table)
 	}
 
+	if processErr != nil {
+		return processErr
+	}
+	if file != nil {
+		err = file.Close()
+		if err != nil {
+			log.Error("Error closing file:", err)
+			return err
+		}
+	}
+	saveCompletedState(processor, stateKey, dataset.Table, saved, log)
+	log.Ok("Write to", dataset.CopyTo, "completed for table:", dataset.Table)
 	return nil
 }
 
-// createOutputFile creates a new file with the given table name and ".sql" extension
-// to write the output SQL statements. It returns the opened file and an error if
-// any.
-func createOutputFile(table string) (*os.File, error) {
-	return os.Create(table + ".sql")
+// processPartitions processes the dataset split into partitions in parallel.
+// Each partition has its own data reader and destination database connection, which runs
+// the session start script when it is opened and the session end script when all partitions have completed.
+// If any partition fails, the other partitions are stopped and the session end scripts are not executed.
+// The progress of each partition is saved in the state with the partition key, and the number of partitions
+// in the state of the dataset, so the resumed dataset continues each partition from its saved position.
+// Partitions are supported only for copying to the database.
+// When the given context is done, all partitions are cancelled, the session end scripts are executed
+// and the saved position of each partition is logged.
+func processPartitions(ctx context.Context, src appconfig.DBConfig, dst appconfig.DBConfig, dataset appconfig.Dataset, index int, stateKey string, saved appstate.DatasetState, resume bool, log *applog.AppLog) error {
+	if dataset.CopyToFileEnabled() {
+		err := fmt.Errorf("parallelism is supported only for copy to db")
+		log.Error("Error splitting into partitions:", err)
+		return err
+	}
+	readers, err := createPartitionReaders(src, dataset, stateKey, saved, resume, log)
+	if err != nil {
+		log.Error("Error splitting into partitions:", err)
+		return err
+	}
+	log.Info("Table split into partitions:", len(readers))
+
+	coordinator := app.PartitionCoordinator{Log: log}
+	rejects := createRejectProcessor(index, dataset, resume && saved.Partitions > 0)
+	if rejects != nil {
+		defer rejects.Close()
+		defer logRejected(rejects, log)
+	}
+	partitionStates := make([]appstate.DatasetState, 0, len(readers))
+	partitionKeys := make([]string, 0, len(readers))
+	dbProcessors := make([]*app.DbProcessor, 0, len(readers))
+	maxBatchBytes := int64(-1)
+	for i, reader := range readers {
+		key := getPartitionKey(stateKey, i)
+		partitionSaved, _ := State.Get(key)
+		if partitionSaved.Completed {
+			log.Warn("Skipping completed partition:", i+1)
+			continue
+		}
+		partitionLog := &applog.AppLog{File: log.File, Id: fmt.Sprintf("%s#%d", log.Id, i+1), Mutex: log.Mutex}
+		writers, err := openDbProcessors(dst, dataset, partitionLog)
+		if err != nil {
+			return err
+		}
+		for _, dbProcessor := range writers {
+			defer dbProcessor.AppDb.Close()
+		}
+		// All partitions write to the same server, so the size is read once
+		if maxBatchBytes < 0 {
+			maxBatchBytes, err = getMaxBatchBytes(writers[0].AppDb, dataset, log)
+			if err != nil {
+				return err
+			}
+		}
+		reader.Retry = createRetryPolicy(dataset, partitionLog)
+		processor := createRowsProcessor(reader, dst, dataset, partitionLog)
+		processor.Dataset.MaxBatchBytes = maxBatchBytes
+		for _, dbProcessor := range writers {
+			writer := createDbWriter(dbProcessor, dataset)
+			if closer, ok := writer.(io.Closer); ok {
+				defer closer.Close()
+			}
+			if dataset.Writers > 1 {
+				processor.Writers = append(processor.Writers, writer)
+			} else {
+				processor.Processors = append(processor.Processors, writer)
+			}
+		}
+		if rejects != nil {
+			processor.Rejects = rejects
+		}
+		processor.DataReader.OnQueryChanged.Subscribe(func(data any) {
+			partitionLog.Info("Query changed. Current query:", data)
+		})
+		subscribeStateSaving(processor, key, dataset.Table, partitionSaved, nil, nil, partitionLog)
+		coordinator.Partitions = append(coordinator.Partitions, processor)
+		partitionStates = append(partitionStates, partitionSaved)
+		partitionKeys = append(partitionKeys, key)
+		dbProcessors = append(dbProcessors, writers...)
+	}
+
+	log.Info("Write to", dataset.CopyTo, "started for table:", dataset.Table, "Partitions:", len(coordinator.Partitions))
+	processErr := coordinator.Process(ctx)
+	cancelled := errors.Is(processErr, app.ErrCancelled)
+	// The partitions that have completed are not processed again when the dataset is resumed
+	for i, processor := range coordinator.Partitions {
+		if coordinator.GetError(i) == nil {
+			saveCompletedState(processor, partitionKeys[i], dataset.Table, partitionStates[i], log)
+		} else if cancelled {
+			logCancelledPosition(partitionKeys[i], log)
+		}
+	}
+	if processErr != nil && !cancelled {
+		log.Error("Error processing partitions:", processErr)
+		return processErr
+	}
+	for _, dbProcessor := range dbProcessors {
+		err = closeDestinationDb(dbProcessor.AppDb, dataset, log)
+		if err != nil {
+			return err
+		}
+	}
+	if cancelled {
+		return processErr
+	}
+	rows := int64(0)
+	for i := range readers {
+		partitionSaved, _ := State.Get(getPartitionKey(stateKey, i))
+		rows += partitionSaved.Rows
+	}
+	err = State.Set(stateKey, appstate.DatasetState{Table: dataset.Table, Rows: rows, Partitions: len(readers), Completed: true})
+	if err != nil {
+		log.Warn("Error saving state:", err)
+	}
+	log.Ok("Write to", dataset.CopyTo, "completed for table:", dataset.Table)
+	return nil
 }
 
-// processRowsAndWriteToFile processes rows from a source database and writes them to a specified file.
-// It initializes a data reader using the provided database configuration and dataset information,
-// and uses a RowsProcessor to manage the data transfer. The function handles opening and closing
-// the data reader, logging errors, and ensuring the proper execution of the data processing logic.
-// It returns an error if any step in the process fails, such as opening the data reader or processing rows.
-func processRowsAndWriteToFile(src appconfig.DBConfig, file *os.File, dataset appconfig.Dataset, log *applog.AppLog) error {
-	dataReader := createDataReader(src, dataset)
-	err := dataReader.Open()
+// createPartitionReaders returns the data readers of the partitions of the dataset.
+// If the dataset is resumed and the partitions have been saved to the state, the readers are restored
+// from the saved positions of the partitions, otherwise the rows of the dataset are split into
+// the number of partitions set by the parallelism of the dataset and the initial positions are saved.
+func createPartitionReaders(src appconfig.DBConfig, dataset appconfig.Dataset, stateKey string, saved appstate.DatasetState, resume bool, log *applog.AppLog) ([]*appdb.DataReader, error) {
+	if resume && saved.Partitions > 0 {
+		readers := make([]*appdb.DataReader, saved.Partitions)
+		for i := range readers {
+			partitionSaved, ok := State.Get(getPartitionKey(stateKey, i))
+			if !ok {
+				return nil, fmt.Errorf("saved state of partition %d not found", i+1)
+			}
+			readers[i] = createDataReader(src, dataset)
+			err := resumeDataReader(readers[i], partitionSaved)
+			if err != nil {
+				return nil, err
+			}
+		}
+		log.Info("Resuming partitions:", len(readers))
+		return readers, nil
+	}
+
+	readers, err := createDataReader(src, dataset).Split(dataset.Parallelism)
 	if err != nil {
-		log.Error("Error opening data reader:", err)
-		return err
+		return nil, err
 	}
-	defer dataReader.Close()
+	for i, reader := range readers {
+		err = State.Set(getPartitionKey(stateKey, i), appstate.DatasetState{Table: dataset.Table, Reader: reader.GetState()})
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = State.Set(stateKey, appstate.DatasetState{Table: dataset.Table, Partitions: len(readers)})
+	return readers, err
+}
+
+// getPartitionKey returns the key of the state of the partition with the given 0-based index.
+func getPartitionKey(stateKey string, index int) string {
+	return fmt.Sprintf("%s#%d", stateKey, index+1)
+}
 
-	processor := app.RowsProcessor{
-		Processor:  &app.FileProcessor{File: file},
+// createRowsProcessor creates the RowsProcessor that reads the rows with the given data reader
+// and formats them for the destination driver according to the dataset configuration.
+func createRowsProcessor(dataReader *appdb.DataReader, dst appconfig.DBConfig, dataset appconfig.Dataset, log *applog.AppLog) *app.RowsProcessor {
+	return &app.RowsProcessor{
 		DataReader: dataReader,
 		Log:        log,
 		Dataset: app.Dataset{
-			InsertCommand:    dataset.InsertCommand,
-			TableName:        dataset.Table,
-			RowsPerCommand:   dataset.Rows,
-			SqlStatementType: dataset.SqlStatement,
+			InsertCommand:     dataset.InsertCommand,
+			TableName:         dataset.Table,
+			Driver:            dst.Driver,
+			RowsPerCommand:    dataset.Rows,
+			SqlStatementType:  dataset.SqlStatement,
+			MaxBatchBytes:     dataset.MaxBatchBytes,
+			Pipeline:          dataset.Pipeline,
+			PipelineDepth:     dataset.PipelineDepth,
+			AdaptiveRows:      dataset.AdaptiveRows,
+			MinRowsPerCommand: dataset.AdaptiveMinRows,
+			MaxRowsPerCommand: dataset.AdaptiveMaxRows,
+			TargetLatency:     time.Duration(dataset.AdaptiveTargetLatency) * time.Millisecond,
+			OnSinkError:       dataset.OnSinkError,
+			OnRowError:        dataset.OnRowError,
 		},
 	}
+}
 
-	processor.DataReader.OnQueryChanged.Subscribe(func(data any) {
-		log.Info("Query changed. Current query:", data)
-	})
+// createRejectProcessor creates the writer of the rows rejected by the destination database
+// if the dataset writes them to the dead letter file, otherwise it returns nil.
+// The file is <table>.rejected.jsonl in the output directory of the dataset.
+// If appending is true, the rejected rows are appended to the file of the previous run.
+func createRejectProcessor(index int, dataset appconfig.Dataset, appending bool) *app.RejectProcessor {
+	if dataset.OnRowError != appconfig.ROW_ERROR_DEADLETTER || !dataset.CopyToDbEnabled() {
+		return nil
+	}
+	fp := appfilepath.AppFilePath{
+		Path: filepath.Join(dataset.OutputDir, "{{table}}"+REJECTED_FILE_SUFFIX),
+		Time: RunTime,
+	}
+	return &app.RejectProcessor{
+		Path: fp.GetFromTemplate(map[string]string{
+			"table":         dataset.Table,
+			"dataset_index": strconv.Itoa(index),
+			"run_id":        RunId,
+		}),
+		Append:     appending,
+		MaxRejects: dataset.MaxRejects,
+	}
+}
 
-	err = processor.Process()
-	if err != nil {
-		log.Error("Error processing rows:", err)
-		return err
+// logRejected logs the number of the rows rejected by the destination database and the file they are written to.
+func logRejected(rejects *app.RejectProcessor, log *applog.AppLog) {
+	if rejects != nil && rejects.GetCount() > 0 {
+		log.Warn("Rows rejected:", rejects.GetCount(), "File:", rejects.Path)
 	}
+}
+
+// createDbProcessor creates the processor that writes rows to the given destination database.
+// The retries of the failed batches are logged with the given log. SQLite commits the batches in transactions
+// by default, except with several writers, as a transaction of one writer would lock the database for the others.
+func createDbProcessor(db *appdb.AppDb, dst appconfig.DBConfig, dataset appconfig.Dataset, log *applog.AppLog) *app.DbProcessor {
+	dbProcessor := &app.DbProcessor{
+		AppDb:        db,
+		TableName:    dataset.Table,
+		WriteTimeout: time.Duration(dataset.WriteTimeout) * time.Second,
+		Retry:        createRetryPolicy(dataset, log),
+		SessionStart: dataset.OnInsertSessionStart,
+	}
+	if dataset.TransactionMode == appconfig.TRANSACTION_MODE_DATASET {
+		dbProcessor.DatasetTransaction = true
+	} else if dataset.TransactionBatches > 0 {
+		dbProcessor.BatchesPerTransaction = dataset.TransactionBatches
+	} else if dst.Driver == appdb.DRIVER_SQLITE && dataset.Writers <= 1 {
+		dbProcessor.BatchesPerTransaction = SQLITE_BATCHES_PER_TRANSACTION
+	}
+	return dbProcessor
+}
+
+// createDbWriter returns the processor that writes the rows of the dataset with the write method of the dataset
+// to the connection of the given processor: the processor itself for INSERT statements or the processor
+// of LOAD DATA LOCAL INFILE for "load_data" or the processor of the native ClickHouse batches for "native".
+// The processor of the native batches opens its own connection, which has to be closed.
+func createDbWriter(dbProcessor *app.DbProcessor, dataset appconfig.Dataset) app.RowsProcessorInterface {
+	switch dataset.WriteMethod {
+	case appconfig.WRITE_METHOD_LOAD_DATA:
+		return &app.LoadDataProcessor{DbProcessor: dbProcessor, InsertCommand: dataset.InsertCommand}
+	case appconfig.WRITE_METHOD_NATIVE:
+		return &app.ClickHouseProcessor{DbProcessor: dbProcessor, InsertCommand: dataset.InsertCommand, Settings: dataset.InsertSettings}
+	}
+	return dbProcessor
+}
 
+// checkWriteMethod returns an error if the write method of the dataset is not supported by the destination:
+// "load_data" is supported only by MySQL and "native" only by ClickHouse.
+func checkWriteMethod(dst appconfig.DBConfig, dataset appconfig.Dataset) error {
+	if !dataset.CopyToDbEnabled() {
+		return nil
+	}
+	drivers := map[string]string{
+		appconfig.WRITE_METHOD_LOAD_DATA: appdb.DRIVER_MYSQL,
+		appconfig.WRITE_METHOD_NATIVE:    appdb.DRIVER_CLICKHOUSE,
+	}
+	if driver, ok := drivers[dataset.WriteMethod]; ok && dst.Driver != driver {
+		return fmt.Errorf("write method %s is supported only for %s", dataset.WriteMethod, driver)
+	}
 	return nil
 }
 
-// processRowsAndWriteToDb processes rows from a source database and writes them to a destination database.
-// It initializes a data reader using the provided source database configuration and dataset information,
-// connects to the destination database using the provided configuration, and uses a RowsProcessor to manage
-// the data transfer. The function handles opening and closing the data reader, connecting to the destination database,
-// logging errors, and ensuring the proper execution of the data processing logic. It returns an error if any step in
-// the process fails, such as opening the data reader, connecting to the destination database, or processing rows.
-func processRowsAndWriteToDb(src appconfig.DBConfig, dst appconfig.DBConfig, dataset appconfig.Dataset, log *applog.AppLog) error {
-	dataReader := createDataReader(src, dataset)
-	err := dataReader.Open()
-	if err != nil {
-		log.Error("Error opening data reader:", err)
-		return err
+// checkTransactions returns an error if the transactions of the dataset are not supported by the destination.
+// ClickHouse has no transactions, so the batches could not be rolled back. The transaction of the whole dataset
+// needs one connection to the destination, as the transactions of several connections are committed one by one.
+// A transaction of one of several writers would lock SQLite for the other writers until it is committed.
+func checkTransactions(dst appconfig.DBConfig, dataset appconfig.Dataset) error {
+	if dataset.TransactionMode != appconfig.TRANSACTION_MODE_DATASET && dataset.TransactionBatches <= 0 {
+		return nil
 	}
-	defer dataReader.Close()
+	if !dataset.CopyToDbEnabled() {
+		return nil
+	}
+	if dst.Driver == appdb.DRIVER_CLICKHOUSE {
+		return fmt.Errorf("transactions are not supported by %s", dst.Driver)
+	}
+	if dataset.TransactionMode == appconfig.TRANSACTION_MODE_DATASET && (dataset.Writers > 1 || dataset.Parallelism > 1) {
+		return fmt.Errorf("transaction mode %s is not supported with writers or parallelism", dataset.TransactionMode)
+	}
+	if dst.Driver == appdb.DRIVER_SQLITE && dataset.Writers > 1 {
+		return fmt.Errorf("transactions are not supported by %s with writers", dst.Driver)
+	}
+	return nil
+}
+
+// openDbProcessors opens the connections of the writers of the dataset to the destination database
+// and creates their processors. Each connection runs the session start script when it is opened.
+// If a connection cannot be opened, the connections opened before it are closed.
+func openDbProcessors(dst appconfig.DBConfig, dataset appconfig.Dataset, log *applog.AppLog) ([]*app.DbProcessor, error) {
+	writers := max(dataset.Writers, 1)
+	dbProcessors := make([]*app.DbProcessor, 0, writers)
+	for range writers {
+		db, err := openDestinationDb(dst, dataset, log)
+		if err != nil {
+			for _, dbProcessor := range dbProcessors {
+				dbProcessor.AppDb.Close()
+			}
+			return nil, err
+		}
+		dbProcessors = append(dbProcessors, createDbProcessor(db, dst, dataset, log))
+	}
+	return dbProcessors, nil
+}
+
+// createRetryPolicy creates the policy of retrying the queries and the batches of the dataset
+// failed with a transient error. Each retry is logged with the given log.
+func createRetryPolicy(dataset appconfig.Dataset, log *applog.AppLog) appdb.RetryPolicy {
+	return appdb.RetryPolicy{
+		MaxAttempts: dataset.RetryMaxAttempts,
+		BaseDelay:   time.Duration(dataset.RetryBaseDelay) * time.Millisecond,
+		MaxDelay:    time.Duration(dataset.RetryMaxDelay) * time.Millisecond,
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			log.Warn("Retrying after error:", dataset.Table, "Attempt:", attempt, "Delay:", delay.Round(time.Millisecond), "Error:", err)
+		},
+	}
+}
+
+// getStateKey returns the key of the dataset state built from the index of the dataset in the config and the table name.
+func getStateKey(index int, dataset appconfig.Dataset) string {
+	return fmt.Sprintf("%d:%s", index, dataset.Table)
+}
+
+// getOutputName returns the path of the output files of the dataset without the extension.
+// The name is built from the output file template of the dataset in the output directory,
+// which is created if it does not exist. If the output files exist, the output overwrite action
+// of the dataset is applied: the files are overwritten, an error is returned or a number suffix is added.
+// If appending is true, the name saved in the state is returned, so the resumed run writes to the same files.
+func getOutputName(index int, dataset appconfig.Dataset, saved appstate.DatasetState, appending bool) (string, error) {
+	if appending && saved.FileName != "" {
+		return saved.FileName, nil
+	}
+	fp := appfilepath.AppFilePath{
+		Path: filepath.Join(dataset.OutputDir, dataset.GetOutputFileTemplate()),
+		Time: RunTime,
+	}
+	fp.Path = fp.GetFromTemplate(map[string]string{
+		"table":         dataset.Table,
+		"dataset_index": strconv.Itoa(index),
+		"run_id":        RunId,
+	})
+	err := os.MkdirAll(filepath.Dir(fp.Path), 0755)
+	if err != nil || appending {
+		return fp.Path, err
+	}
+	suffixes := getOutputSuffixes(dataset)
+	switch dataset.GetOutputOverwrite() {
+	case appconfig.OUTPUT_OVERWRITE:
+		return fp.Path, nil
+	case appconfig.OUTPUT_FAIL:
+		if fp.Exists(suffixes) {
+			return "", fmt.Errorf("output file exists: %s", fp.Path+suffixes[0])
+		}
+		return fp.Path, nil
+	case appconfig.OUTPUT_SUFFIX:
+		return fp.GetAvailable(suffixes), nil
+	default:
+		return "", fmt.Errorf("unknown output overwrite action: %s", dataset.OutputOverwrite)
+	}
+}
+
+// getOutputSuffixes returns the suffixes added to the output name to get the names of the output files of the dataset.
+// These are the extension of the output file or the first part and the manifest if the output is split into several files.
+func getOutputSuffixes(dataset appconfig.Dataset) []string {
+	if dataset.SplitEnabled() {
+		return []string{getPartFileName("", dataset, 1), getManifestFileName("")}
+	}
+	return []string{getOutputFileName("", dataset)}
+}
+
+// getOutputFileName returns the name of the output file of the dataset with the given output name.
+// The name is the output name with the extension of the file format and the compression,
+// for example "table.sql", "table.csv" or "table.sql.gz".
+func getOutputFileName(name string, dataset appconfig.Dataset) string {
+	return name + "." + dataset.GetFileFormat() + appfile.GetExtension(dataset.Compression)
+}
+
+// getPartFileName returns the name of the part file with the given number of the dataset split into several files.
+// The number is inserted before the extension of the file format, for example "table.0001.sql" or "table.0002.csv.gz".
+func getPartFileName(name string, dataset appconfig.Dataset, number int) string {
+	return fmt.Sprintf("%s.%04d.%s%s", name, number, dataset.GetFileFormat(), appfile.GetExtension(dataset.Compression))
+}
+
+// getManifestFileName returns the name of the manifest file of the dataset split into several files.
+func getManifestFileName(name string) string {
+	return name + ".manifest.json"
+}
+
+// createSplitProcessor creates the processor that splits the output of the dataset into part files
+// in the file format of the dataset. If appending is true, the parts saved in the state are restored
+// and the current part is appended. It returns the split processor and the processor to be added
+// to the RowsProcessor, which writes SQL statements or row values depending on the file format.
+func createSplitProcessor(dataset appconfig.Dataset, saved appstate.DatasetState, appending bool) (*app.SplitProcessor, app.RowsProcessorInterface, error) {
+	split := &app.SplitProcessor{
+		MaxRows:      dataset.MaxRowsPerFile,
+		MaxBytes:     dataset.MaxBytesPerFile,
+		ManifestPath: getManifestFileName(saved.FileName),
+		CreatePart: func(number int, appending bool, size int64) (app.RowsProcessorInterface, app.SplitFileInterface, error) {
+			file, err := openOutputFile(getPartFileName(saved.FileName, dataset, number), dataset, size, appending)
+			if err != nil {
+				return nil, nil, err
+			}
+			fileProcessor, err := createFileProcessor(dataset, file, appending)
+			if err != nil {
+				file.Close()
+				return nil, nil, err
+			}
+			return fileProcessor, file, nil
+		},
+	}
+	if appending {
+		if err := split.Resume(saved.FileParts); err != nil {
+			return nil, nil, err
+		}
+	}
+	if dataset.GetFileFormat() == appconfig.FILE_FORMAT_SQL {
+		return split, split, nil
+	}
+	return split, &app.SplitRowsProcessor{SplitProcessor: split}, nil
+}
 
-	// Connect to the destination database
-	db := appdb.AppDb{
-		Driver: dst.Driver,
-		Dsn:    dst.DSN,
+// createFileProcessor creates the processor that writes rows to the given file in the file format of the dataset.
+// If appending is true, the rows are appended to the existing file, so the CSV header is not written again.
+// Parquet files cannot be appended, as the file footer is written at the end.
+// It returns an error if the file format or the CSV delimiter is not valid or the file cannot be appended.
+func createFileProcessor(dataset appconfig.Dataset, file *appfile.AppFile, appending bool) (app.RowsProcessorInterface, error) {
+	switch dataset.GetFileFormat() {
+	case appconfig.FILE_FORMAT_SQL:
+		return &app.FileProcessor{File: file}, nil
+	case appconfig.FILE_FORMAT_JSONL:
+		return &app.JsonlProcessor{File: file}, nil
+	case appconfig.FILE_FORMAT_PARQUET:
+		if appending {
+			return nil, fmt.Errorf("parquet file cannot be resumed, remove the state file or run without -resume")
+		}
+		return &app.ParquetProcessor{File: file, RowGroupSize: dataset.ParquetRowGroupSize}, nil
+	case appconfig.FILE_FORMAT_CSV, appconfig.FILE_FORMAT_TSV:
+		delimiter := []rune(dataset.CsvDelimiter)
+		if len(delimiter) == 0 && dataset.GetFileFormat() == appconfig.FILE_FORMAT_TSV {
+			delimiter = []rune{'\t'}
+		}
+		if len(delimiter) > 1 {
+			return nil, fmt.Errorf("csv delimiter must be one character: %s", dataset.CsvDelimiter)
+		}
+		csvProcessor := &app.CsvProcessor{File: file, NullValue: dataset.CsvNull, SkipHeader: appending}
+		if len(delimiter) == 1 {
+			csvProcessor.Delimiter = delimiter[0]
+		}
+		return csvProcessor, nil
+	default:
+		return nil, fmt.Errorf("unknown file format: %s", dataset.FileFormat)
 	}
-	err = db.Open()
+}
+
+// openOutputFile creates the output file with the given name and the compression of the dataset.
+// If appending is true, the existing output file is truncated to the given size and opened for appending,
+// otherwise the file is created anew. It returns the opened file and an error if any.
+func openOutputFile(fileName string, dataset appconfig.Dataset, size int64, appending bool) (*appfile.AppFile, error) {
+	file := &appfile.AppFile{
+		Path:        fileName,
+		Compression: dataset.Compression,
+		Level:       dataset.CompressionLevel,
+	}
+	if appending {
+		return file, file.Resume(size)
+	}
+	return file, file.Create()
+}
+
+// openDestinationDb connects to the destination database using the provided configuration
+// and executes the on_insert_session_start script of the dataset.
+// It returns the opened database connection and an error if any step fails.
+func openDestinationDb(dst appconfig.DBConfig, dataset appconfig.Dataset, log *applog.AppLog) (*appdb.AppDb, error) {
+	db := &appdb.AppDb{
+		Driver:             dst.Driver,
+		Dsn:                dst.DSN,
+		KillQueryOnTimeout: dataset.KillQueryOnTimeout,
+	}
+	err := db.Open()
 	if err != nil {
 		log.Error("Error connecting to the database:", err)
-		return err
+		return nil, err
 	}
-	defer db.Close()
 
 	if dataset.OnInsertSessionStart != "" {
 		err = db.ExecMultiple(dataset.OnInsertSessionStart)
 		if err != nil {
 			log.Error("Error executing on_insert_session_start:", err)
-			return err
+			db.Close()
+			return nil, err
 		}
 	}
+	return db, nil
+}
 
-	processor := app.RowsProcessor{
-		Processor:  &app.DbProcessor{AppDb: &db, TableName: dataset.Table},
-		DataReader: dataReader,
-		Log:        log,
-		Dataset: app.Dataset{
-			InsertCommand:    dataset.InsertCommand,
-			TableName:        dataset.Table,
-			RowsPerCommand:   dataset.Rows,
-			SqlStatementType: dataset.SqlStatement,
-		},
+// getMaxBatchBytes returns the max size in bytes of one INSERT statement of the dataset.
+// If read_max_allowed_packet is set, the max statement size of the given destination database
+// minus MAX_PACKET_RESERVE is used as the ceiling of max_batch_bytes.
+func getMaxBatchBytes(db *appdb.AppDb, dataset appconfig.Dataset, log *applog.AppLog) (int64, error) {
+	if !dataset.ReadMaxAllowedPacket {
+		return dataset.MaxBatchBytes, nil
 	}
+	size, err := db.GetMaxStatementSize()
+	if err != nil {
+		log.Error("Error reading max_allowed_packet:", err)
+		return 0, err
+	}
+	if size <= MAX_PACKET_RESERVE {
+		return dataset.MaxBatchBytes, nil
+	}
+	maxBatchBytes := size - MAX_PACKET_RESERVE
+	if dataset.MaxBatchBytes > 0 {
+		maxBatchBytes = min(maxBatchBytes, dataset.MaxBatchBytes)
+	}
+	log.Info("Max batch size in bytes:", maxBatchBytes, "max_allowed_packet:", size)
+	return maxBatchBytes, nil
+}
 
-	processor.DataReader.OnQueryChanged.Subscribe(func(data any) {
-		log.Info("Query changed. Current query:", data)
-	})
-
-	err = processor.Process()
+// closeDestinationDb executes the on_insert_session_end script of the dataset on the destination database.
+// It returns an error if the script fails.
+func closeDestinationDb(db *appdb.AppDb, dataset appconfig.Dataset, log *applog.AppLog) error {
+	if dataset.OnInsertSessionEnd == "" {
+		return nil
+	}
+	err := db.ExecMultiple(dataset.OnInsertSessionEnd)
 	if err != nil {
-		log.Error("Error processing rows:", err)
+		log.Error("Error executing on_insert_session_end:", err)
 		return err
 	}
+	return nil
+}
+
+// getResumeState returns the saved state of the dataset with the given key.
+// The second return value is true if the state was saved by a previous run and
+// the dataset should be resumed from the saved position.
+func getResumeState(key string, log *applog.AppLog) (appstate.DatasetState, bool) {
+	saved, ok := State.Get(key)
+	if ok && !saved.Completed {
+		log.Info("Resuming from saved position:", key, "Rows:", saved.Rows)
+	}
+	return saved, ok
+}
 
-	if dataset.OnInsertSessionEnd != "" {
-		err = db.ExecMultiple(dataset.OnInsertSessionEnd)
+// logCancelledPosition logs the position of the cancelled dataset or partition with the given key
+// saved after the last written batch, from which the dataset continues when it is resumed.
+func logCancelledPosition(key string, log *applog.AppLog) {
+	saved, _ := State.Get(key)
+	log.Warn("Cancelled at position:", saved.Reader.String(), "Key:", key, "Rows:", saved.Rows)
+}
+
+// resumeDataReader moves the data reader to the saved position if the saved position is not empty.
+// Returns an error if the saved state does not match the data reader configuration.
+func resumeDataReader(dataReader *appdb.DataReader, saved appstate.DatasetState) error {
+	if saved.Reader.IsEmpty() {
+		return nil
+	}
+	return dataReader.Resume(saved.Reader)
+}
+
+// subscribeStateSaving saves the position of the data reader to the state after each written batch.
+// The rows processed in the current run up to the end of the written batch are added to the rows count of the saved state.
+// If file is not nil, the size of the file is saved to truncate the file on resume.
+// If split is not nil, the parts of the split output are saved to continue the current part on resume.
+func subscribeStateSaving(processor *app.RowsProcessor, key string, table string, saved appstate.DatasetState, file *appfile.AppFile, split *app.SplitProcessor, log *applog.AppLog) {
+	processor.OnBatchWritten.Subscribe(func(data any) {
+		progress := data.(app.BatchProgress)
+		datasetState := appstate.DatasetState{
+			Table:    table,
+			Reader:   progress.State,
+			Rows:     saved.Rows + progress.Rows,
+			FileName: saved.FileName,
+		}
+		if file != nil {
+			size, err := file.Size()
+			if err != nil {
+				log.Warn("Error saving state:", err)
+				return
+			}
+			datasetState.FileSize = size
+		}
+		if split != nil {
+			parts, err := split.GetParts()
+			if err != nil {
+				log.Warn("Error saving state:", err)
+				return
+			}
+			datasetState.FileParts = parts
+		}
+		err := State.Set(key, datasetState)
 		if err != nil {
-			log.Error("Error executing on_insert_session_end:", err)
-			return err
+			log.Warn("Error saving state:", err)
 		}
-	}
+	})
+}
 
-	return nil
+// saveCompletedState marks the dataset with the given key as completed in the state.
+func saveCompletedState(processor *app.RowsProcessor, key string, table string, saved appstate.DatasetState, log *applog.AppLog) {
+	err := State.Set(key, appstate.DatasetState{
+		Table:     table,
+		Reader:    processor.DataReader.GetState(),
+		Rows:      saved.Rows + processor.GetRowsCount(),
+		FileName:  saved.FileName,
+		Completed: true,
+	})
+	if err != nil {
+		log.Warn("Error saving state:", err)
+	}
 }
 
 // createDataReader creates a new DataReader instance using the provided database
@@ -330,19 +1101,26 @@ func processRowsAndWriteToDb(src appconfig.DBConfig, dst appconfig.DBConfig, dat
 func createDataReader(dbConf appconfig.DBConfig, dataset appconfig.Dataset) *appdb.DataReader {
 	return &appdb.DataReader{
 		AppDb: &appdb.AppDb{
-			Driver: dbConf.Driver,
-			Dsn:    dbConf.DSN,
+			Driver:             dbConf.Driver,
+			Dsn:                dbConf.DSN,
+			KillQueryOnTimeout: dataset.KillQueryOnTimeout,
 		},
-		Query:           dataset.Query,
-		QueryType:       dataset.QueryType,
-		ExecutionTime:   dataset.ExecutionTime,
-		ResetConnection: dataset.ResetConnection,
-		Limit:           dataset.Limit,
-		InitialId:       dataset.InitialId,
-		InitialOffset:   dataset.InitialOffset,
-		MaxOffset:       dataset.MaxOffset,
-		BetweenStart:    dataset.BetweenStart,
-		BetweenEnd:      dataset.BetweenEnd,
-		BetweenStep:     dataset.BetweenStep,
+		Query:            dataset.Query,
+		QueryType:        dataset.QueryType,
+		ExecutionTime:    dataset.ExecutionTime,
+		ResetConnection:  dataset.ResetConnection,
+		Limit:            dataset.Limit,
+		InitialId:        dataset.InitialId,
+		KeyColumns:       dataset.KeyColumns,
+		InitialKey:       dataset.InitialKey,
+		InitialOffset:    dataset.InitialOffset,
+		MaxOffset:        dataset.MaxOffset,
+		BetweenStart:     dataset.BetweenStart,
+		BetweenEnd:       dataset.BetweenEnd,
+		BetweenStep:      dataset.BetweenStep,
+		BetweenColumn:    dataset.BetweenColumn,
+		BetweenChunkRows: dataset.BetweenChunkRows,
+		BetweenRowCount:  dataset.BetweenRowCount,
+		QueryTimeout:     time.Duration(dataset.QueryTimeout) * time.Second,
 	}
 }
@@ -0,0 +1,51 @@
+// Description: This package provides main entry point for the application.
+// Developer: Aleksei Grigorev <https://github.com/AlekseiGrigorev>, <aleksvgrig@gmail.com>
+// Copyright (c) 2025 Aleksei Grigorev
+package main
+
+import (
+	"copysqldatatool/internal/appconfig"
+	"copysqldatatool/internal/appdb"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetDatasetConnections verifies that a dataset opens a source and a destination connection
+// for every partition and a destination connection for each writer of a partition.
+func TestGetDatasetConnections(t *testing.T) {
+	tests := []struct {
+		parallelism int
+		writers     int
+		copyTo      string
+		expected    map[string]int
+	}{
+		{0, 0, appconfig.COPY_TO_DB, map[string]int{POOL_CONNECTION_SOURCE: 1, POOL_CONNECTION_DEST: 1}},
+		{3, 1, appconfig.COPY_TO_DB, map[string]int{POOL_CONNECTION_SOURCE: 3, POOL_CONNECTION_DEST: 3}},
+		{1, 8, appconfig.COPY_TO_DB, map[string]int{POOL_CONNECTION_SOURCE: 1, POOL_CONNECTION_DEST: 8}},
+		{3, 4, appconfig.COPY_TO_DB, map[string]int{POOL_CONNECTION_SOURCE: 3, POOL_CONNECTION_DEST: 12}},
+		{3, 4, appconfig.COPY_TO_FILE, map[string]int{POOL_CONNECTION_SOURCE: 3}},
+	}
+	for _, test := range tests {
+		dataset := appconfig.Dataset{Enabled: true, Table: "t", Query: "SELECT * FROM t", CopyTo: test.copyTo, Parallelism: test.parallelism, Writers: test.writers}
+		assert.Equal(t, test.expected, getDatasetConnections(dataset), test)
+	}
+	assert.Nil(t, getDatasetConnections(appconfig.Dataset{Table: "t", Query: "SELECT * FROM t", Writers: 8}))
+}
+
+// TestCheckTransactions verifies that the transactions of several SQLite writers are rejected
+// and that the SQLite writers are not committed in the default transactions.
+func TestCheckTransactions(t *testing.T) {
+	dst := appconfig.DBConfig{Driver: appdb.DRIVER_SQLITE}
+	dataset := appconfig.Dataset{CopyTo: appconfig.COPY_TO_DB, Writers: 3, TransactionBatches: 4}
+	assert.ErrorContains(t, checkTransactions(dst, dataset), "not supported")
+	dataset.Writers = 1
+	assert.Nil(t, checkTransactions(dst, dataset))
+	assert.Equal(t, int64(4), createDbProcessor(nil, dst, dataset, nil).BatchesPerTransaction)
+
+	dataset.TransactionBatches = 0
+	assert.Equal(t, int64(SQLITE_BATCHES_PER_TRANSACTION), createDbProcessor(nil, dst, dataset, nil).BatchesPerTransaction)
+	dataset.Writers = 3
+	assert.Nil(t, checkTransactions(dst, dataset))
+	assert.Equal(t, int64(0), createDbProcessor(nil, dst, dataset, nil).BatchesPerTransaction)
+}